@@ -5,15 +5,7 @@
 
 void api_destruct_handler(void *state) {}
 
-/* Comparator */
-
-rocksdb_comparator_t *api_comparator_create(uintptr_t idx) {
-  return rocksdb_comparator_create(
-    (void *)idx,
-    api_destruct_handler,
-    (int (*)(void *, const char *, size_t, const char *, size_t))(itf_comparator_compare),
-    (const char *(*)(void *))(itf_comparator_name));
-}
+/* Comparator: see api_comparator.cc */
 
 /* Merge Operator */
 
@@ -46,7 +38,7 @@ void itf_filterpolicy_delete_filter(void *state, const char *v, size_t s) {}
 rocksdb_filterpolicy_t *api_filterpolicy_create(uintptr_t idx) {
   return rocksdb_filterpolicy_create(
     (void *)idx,
-    api_destruct_handler,
+    (void (*)(void *))(itf_filterpolicy_destroy),
     (char *(*)(void *, const char *const *, const size_t *, int, size_t *))(itf_filterpolicy_create_filter),
     (unsigned char (*)(void *, const char *, size_t, const char *, size_t))(itf_filterpolicy_key_may_match),
     itf_filterpolicy_delete_filter,
@@ -4,9 +4,36 @@
 
 /* Base */
 
+// api_property_map reads a map property of cf, or of the default column
+// family if cf is NULL. The keys and values are stored interleaved in a
+// malloc'd array of *n pairs of malloc'd strings. It returns 0 if the
+// property is unknown.
+extern unsigned char api_property_map(rocksdb_t* db, rocksdb_column_family_handle_t* cf, const char* name, char*** kvs, size_t* n);
+
+/* Column Family */
+
+// api_column_family_id and api_column_family_name return the ID and the
+// name of cf, which the C API of RocksDB 6 does not expose. The name is a
+// malloc'd string of *len bytes.
+extern uint32_t api_column_family_id(rocksdb_column_family_handle_t* cf);
+extern char* api_column_family_name(rocksdb_column_family_handle_t* cf, size_t* len);
+
+/* Snapshot */
+
+// api_snapshot_sequence_number returns the sequence number of snap, which
+// the C API of RocksDB 6 does not expose.
+extern uint64_t api_snapshot_sequence_number(const rocksdb_snapshot_t* snap);
+
+/* Transaction */
+
+// api_transaction_single_delete records a single delete of key in txn, as
+// the C API only offers plain deletes on transactions.
+extern void api_transaction_single_delete(rocksdb_transaction_t* txn, const char* key, size_t klen, char** errptr);
+
 /* Comparator */
 
 extern rocksdb_comparator_t* api_comparator_create(uintptr_t idx);
+extern void api_comparator_destroy(rocksdb_comparator_t* cmp);
 
 /* Merge Operator */
 
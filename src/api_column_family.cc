@@ -0,0 +1,25 @@
+#include <stdlib.h>
+#include <string.h>
+#include <string>
+
+#include "api_handles.h"
+
+extern "C" {
+#include "api.h"
+}
+
+// The C API has no accessors for the ID and the name of a column family,
+// so they are read in C++.
+
+uint32_t api_column_family_id(rocksdb_column_family_handle_t* cf) {
+  return cf->rep->GetID();
+}
+
+char* api_column_family_name(rocksdb_column_family_handle_t* cf, size_t* len) {
+  const std::string& name = cf->rep->GetName();
+  *len = name.size();
+  char* c = static_cast<char*>(malloc(name.size() + 1));
+  memcpy(c, name.data(), name.size());
+  c[name.size()] = '\0';
+  return c;
+}
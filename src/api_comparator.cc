@@ -0,0 +1,55 @@
+#include <stdlib.h>
+#include <string>
+
+#include "rocksdb/comparator.h"
+#include "rocksdb/slice.h"
+
+extern "C" {
+#include "api.h"
+#include "_cgo_export.h"
+}
+
+// The C API comparator does not forward FindShortestSeparator and
+// FindShortSuccessor, so Go comparators are implemented in C++.
+class GoComparator : public rocksdb::Comparator {
+ public:
+  explicit GoComparator(uintptr_t idx) : idx_(idx) {}
+
+  int Compare(const rocksdb::Slice& a, const rocksdb::Slice& b) const override {
+    return itf_comparator_compare(idx_, const_cast<char*>(a.data()), a.size(), const_cast<char*>(b.data()), b.size());
+  }
+
+  const char* Name() const override {
+    return itf_comparator_name(idx_);
+  }
+
+  void FindShortestSeparator(std::string* start, const rocksdb::Slice& limit) const override {
+    size_t len = 0;
+    char* sep = itf_comparator_separator(idx_, const_cast<char*>(start->data()), start->size(), const_cast<char*>(limit.data()), limit.size(), &len);
+    if (sep != nullptr) {
+      start->assign(sep, len);
+      free(sep);
+    }
+  }
+
+  void FindShortSuccessor(std::string* key) const override {
+    size_t len = 0;
+    char* succ = itf_comparator_successor(idx_, const_cast<char*>(key->data()), key->size(), &len);
+    if (succ != nullptr) {
+      key->assign(succ, len);
+      free(succ);
+    }
+  }
+
+ private:
+  uintptr_t idx_;
+};
+
+rocksdb_comparator_t* api_comparator_create(uintptr_t idx) {
+  rocksdb::Comparator* cmp = new GoComparator(idx);
+  return reinterpret_cast<rocksdb_comparator_t*>(cmp);
+}
+
+void api_comparator_destroy(rocksdb_comparator_t* cmp) {
+  delete reinterpret_cast<rocksdb::Comparator*>(cmp);
+}
@@ -0,0 +1,29 @@
+#ifndef API_HANDLES_H
+#define API_HANDLES_H
+
+#include "rocksdb/db.h"
+#include "rocksdb/utilities/transaction.h"
+
+// The C API does not reach everything the wrapper needs, so a few functions
+// are written in C++ against the objects behind the C API handles. The
+// handles are defined privately in rocksdb/c.cc; the definitions below must
+// match it for the RocksDB 6 releases the package supports, and are the only
+// ones in this package. Only rep is ever read.
+
+struct rocksdb_t {
+  rocksdb::DB* rep;
+};
+
+struct rocksdb_column_family_handle_t {
+  rocksdb::ColumnFamilyHandle* rep;
+};
+
+struct rocksdb_snapshot_t {
+  const rocksdb::Snapshot* rep;
+};
+
+struct rocksdb_transaction_t {
+  rocksdb::Transaction* rep;
+};
+
+#endif  // API_HANDLES_H
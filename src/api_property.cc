@@ -0,0 +1,35 @@
+#include <stdlib.h>
+#include <string.h>
+#include <map>
+#include <string>
+
+#include "api_handles.h"
+
+extern "C" {
+#include "api.h"
+}
+
+// The C API does not expose map properties, so they are read in C++.
+
+static char* copy_string(const std::string& s) {
+  char* c = static_cast<char*>(malloc(s.size() + 1));
+  memcpy(c, s.data(), s.size());
+  c[s.size()] = '\0';
+  return c;
+}
+
+unsigned char api_property_map(rocksdb_t* db, rocksdb_column_family_handle_t* cf, const char* name, char*** kvs, size_t* n) {
+  std::map<std::string, std::string> props;
+  rocksdb::ColumnFamilyHandle* handle = cf != nullptr ? cf->rep : db->rep->DefaultColumnFamily();
+  if (!db->rep->GetMapProperty(handle, rocksdb::Slice(name), &props)) {
+    return 0;
+  }
+  *n = props.size();
+  *kvs = static_cast<char**>(malloc(2 * props.size() * sizeof(char*) + 1));
+  size_t i = 0;
+  for (const auto& kv : props) {
+    (*kvs)[i++] = copy_string(kv.first);
+    (*kvs)[i++] = copy_string(kv.second);
+  }
+  return 1;
+}
@@ -0,0 +1,12 @@
+#include "api_handles.h"
+
+extern "C" {
+#include "api.h"
+}
+
+// The C API has no accessor for the sequence number of a snapshot, so it
+// is read in C++.
+
+uint64_t api_snapshot_sequence_number(const rocksdb_snapshot_t* snap) {
+  return snap->rep->GetSequenceNumber();
+}
@@ -0,0 +1,17 @@
+#include <stdlib.h>
+#include <string.h>
+
+#include "api_handles.h"
+
+extern "C" {
+#include "api.h"
+}
+
+// The C API has no single delete for transactions, so it is written in C++.
+
+void api_transaction_single_delete(rocksdb_transaction_t* txn, const char* key, size_t klen, char** errptr) {
+  rocksdb::Status s = txn->rep->SingleDelete(rocksdb::Slice(key, klen));
+  if (!s.ok()) {
+    *errptr = strdup(s.ToString().c_str());
+  }
+}
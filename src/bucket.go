@@ -0,0 +1,158 @@
+package rocksdb
+
+//#include "api.h"
+//#include <stdlib.h>
+import "C"
+import (
+	"unsafe"
+	"./errors"
+	"./iterator"
+	"./opt"
+	. "./util"
+)
+
+// Bucket is a goleveldb style view of a column family of a DB, created by
+// DB.Bucket. Its methods behave like the DB methods of the same name, but
+// read and write the column family only.
+//
+// A Bucket is safe for concurrent use, it stays valid until the DB is
+// closed.
+type Bucket struct {
+	db   *DB
+	name string
+	cf   *ColumnFamilyHandle
+}
+
+// Bucket returns the bucket backed by the named column family, creating
+// the column family, with the options the DB was opened with, if it does
+// not exist yet. The default column family is a bucket too, except in DBs
+// opened with OpenDb or OpenDbForReadOnly, which hold no handle for it.
+func (db *DB) Bucket(name string) (*Bucket, error) {
+	if cf := db.cfs.get(name); cf != nil {
+		return &Bucket{db: db, name: name, cf: cf}, nil
+	}
+	db.cfs.createMu.Lock()
+	defer db.cfs.createMu.Unlock()
+	cf := db.cfs.get(name)
+	if cf == nil {
+		var err error
+		if cf, err = db.CreateColumnFamily(db.opts, name); err != nil {
+			return nil, err
+		}
+	}
+	return &Bucket{db: db, name: name, cf: cf}, nil
+}
+
+// Name returns the name of the bucket, which is the name of its column
+// family.
+func (b *Bucket) Name() string {
+	return b.name
+}
+
+// ColumnFamily returns the handle of the column family of the bucket.
+func (b *Bucket) ColumnFamily() *ColumnFamilyHandle {
+	return b.cf
+}
+
+// Get returns the data associated with the key from the bucket.
+// It returns ErrNotFound if the bucket does not contain the key.
+func (b *Bucket) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+	var (
+		cErr *C.char
+		cKey = byteToChar(key)
+	)
+	cPinned := C.rocksdb_get_pinned_cf(b.db.c, b.db.readOptions(ro).c, b.cf.c, cKey, C.size_t(len(key)), &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
+	}
+	if cPinned == nil {
+		return nil, errors.ErrNotFound
+	}
+	defer C.rocksdb_pinnableslice_destroy(cPinned)
+	var cValLen C.size_t
+	cValue := C.rocksdb_pinnableslice_value(cPinned, &cValLen)
+	return C.GoBytes(unsafe.Pointer(cValue), C.int(cValLen)), nil
+}
+
+// Has returns true if the bucket does contain the given key, see DB.Has.
+func (b *Bucket) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	var (
+		cErr    *C.char
+		cValue  *C.char
+		cValLen C.size_t
+		cFound  C.uchar
+		cKey    = byteToChar(key)
+		opts    = b.db.readOptions(ro)
+	)
+	if C.rocksdb_key_may_exist_cf(b.db.c, opts.c, b.cf.c, cKey, C.size_t(len(key)), &cValue, &cValLen, nil, 0, &cFound) == 0 {
+		return false, nil
+	}
+	if cFound != 0 {
+		C.free(unsafe.Pointer(cValue))
+		return true, nil
+	}
+	cPinned := C.rocksdb_get_pinned_cf(b.db.c, opts.c, b.cf.c, cKey, C.size_t(len(key)), &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return false, errors.FromStatus(C.GoString(cErr))
+	}
+	if cPinned == nil {
+		return false, nil
+	}
+	C.rocksdb_pinnableslice_destroy(cPinned)
+	return true, nil
+}
+
+// Put writes data associated with a key to the bucket.
+func (b *Bucket) Put(key, value []byte, wo *opt.WriteOptions) error {
+	return b.db.PutCF(b.db.writeOptions(wo), b.cf, key, value)
+}
+
+// Delete removes the data associated with the key from the bucket.
+func (b *Bucket) Delete(key []byte, wo *opt.WriteOptions) error {
+	return b.db.DeleteCF(b.db.writeOptions(wo), b.cf, key)
+}
+
+// NewIterator returns an Iterator over the bucket, limited to the keys
+// within slice; a nil slice or a nil Limit leaves that side unbounded.
+func (b *Bucket) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	opts := b.db.readOptions(ro)
+	if slice == nil || (len(slice.Start) == 0 && slice.Limit == nil) {
+		return b.db.NewIteratorCF(opts, b.cf)
+	}
+	bounded, free := opts.withBounds(slice)
+	cIter := C.rocksdb_create_iterator_cf(b.db.c, bounded.c, b.cf.c)
+	return iterator.NewNativeIteratorWithCleanup(unsafe.Pointer(cIter), free)
+}
+
+// Batch returns a view of batch whose updates go to the bucket. The
+// updates of several buckets, and of the default column family, can be
+// recorded in one Batch and written atomically with DB.Write.
+//
+// Replay skips the updates of buckets other than the default column
+// family, and DBTransaction.Write rejects batches holding them with
+// ErrNotSupported.
+func (b *Bucket) Batch(batch *Batch) *BucketBatch {
+	return &BucketBatch{batch: batch, cf: b.cf}
+}
+
+// BucketBatch records the updates of a Bucket in a Batch.
+type BucketBatch struct {
+	batch *Batch
+	cf    *ColumnFamilyHandle
+}
+
+// Put appends 'put operation' of the given key/value pair to the batch.
+// It is safe to modify the contents of the argument after Put returns.
+func (bb *BucketBatch) Put(key, value []byte) {
+	bb.batch.init()
+	(*WriteBatch)(bb.batch).PutCF(bb.cf, key, value)
+}
+
+// Delete appends 'delete operation' of the given key to the batch.
+// It is safe to modify the contents of the argument after Delete returns.
+func (bb *BucketBatch) Delete(key []byte) {
+	bb.batch.init()
+	(*WriteBatch)(bb.batch).DeleteCF(bb.cf, key)
+}
@@ -0,0 +1,75 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	. "./constants"
+	"./util"
+)
+
+func TestBucket(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestBucket")
+	ensure.Nil(t, err)
+
+	db, err := OpenFile(dir, nil)
+	ensure.Nil(t, err)
+	users, err := db.Bucket("users")
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, users.Name(), "users")
+	again, err := db.Bucket("users")
+	ensure.Nil(t, err)
+	ensure.True(t, again.ColumnFamily() == users.ColumnFamily())
+
+	ensure.Nil(t, users.Put([]byte("a"), []byte("1"), nil))
+	v, err := users.Get([]byte("a"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("1"))
+	_, err = db.Get([]byte("a"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	has, err := users.Has([]byte("a"), nil)
+	ensure.Nil(t, err)
+	ensure.True(t, has)
+	ensure.Nil(t, users.Delete([]byte("a"), nil))
+	_, err = users.Get([]byte("a"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	// A batch spans the default column family and several buckets.
+	orders, err := db.Bucket("orders")
+	ensure.Nil(t, err)
+	b := NewBatch()
+	defer b.Destroy()
+	b.Put([]byte("k"), []byte("default"))
+	users.Batch(b).Put([]byte("k1"), []byte("user1"))
+	users.Batch(b).Put([]byte("k2"), []byte("user2"))
+	orders.Batch(b).Put([]byte("k"), []byte("order"))
+	ensure.Nil(t, db.Write(b, nil))
+	ensure.Nil(t, db.Close())
+
+	// The buckets are found again on reopen.
+	db, err = OpenFile(dir, nil)
+	ensure.Nil(t, err)
+	defer db.Close()
+	ensure.DeepEqual(t, db.ColumnFamilyNames(), []string{"default", "orders", "users"})
+	orders, err = db.Bucket("orders")
+	ensure.Nil(t, err)
+	v, err = orders.Get([]byte("k"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("order"))
+	v, err = db.Get([]byte("k"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("default"))
+
+	users, err = db.Bucket("users")
+	ensure.Nil(t, err)
+	iter := users.NewIterator(&util.Range{Start: []byte("k2")}, nil)
+	defer iter.Release()
+	ensure.True(t, iter.Next())
+	ensure.DeepEqual(t, iter.Key(), []byte("k2"))
+	ensure.DeepEqual(t, iter.Value(), []byte("user2"))
+	ensure.False(t, iter.Next())
+}
@@ -1,17 +1,21 @@
 package rocksdb
 
 //#include "api.h"
+//#include <stdlib.h>
 import "C"
 import "unsafe"
 
 // ColumnFamilyHandle represents a handle to a ColumnFamily.
 type ColumnFamilyHandle struct {
 	c *C.rocksdb_column_family_handle_t
+
+	// cfs is the registry of the DB that knows the handle, if any.
+	cfs *cfRegistry
 }
 
 // NewNativeColumnFamilyHandle creates a ColumnFamilyHandle object.
 func NewNativeColumnFamilyHandle(c *C.rocksdb_column_family_handle_t) *ColumnFamilyHandle {
-	return &ColumnFamilyHandle{c}
+	return &ColumnFamilyHandle{c: c}
 }
 
 // UnsafeGetCFHandler returns the underlying c column family handle.
@@ -19,7 +23,34 @@ func (h *ColumnFamilyHandle) UnsafeGetCFHandler() unsafe.Pointer {
 	return unsafe.Pointer(h.c)
 }
 
+// Name returns the name of the column family.
+func (h *ColumnFamilyHandle) Name() string {
+	var cLen C.size_t
+	cName := C.api_column_family_name(h.c, &cLen)
+	defer C.free(unsafe.Pointer(cName))
+	return C.GoStringN(cName, C.int(cLen))
+}
+
+// ID returns the ID of the column family, which is unique within the DB
+// and never reused. The default column family has ID 0.
+func (h *ColumnFamilyHandle) ID() uint32 {
+	return uint32(C.api_column_family_id(h.c))
+}
+
 // Destroy calls the destructor of the underlying column family handle.
+// Handles known to a DB are destroyed when it is closed; calling Destroy
+// on such a handle before, or more than once, is safe. A handle destroyed
+// before is no longer returned by DB.ColumnFamily.
 func (h *ColumnFamilyHandle) Destroy() {
-	C.rocksdb_column_family_handle_destroy(h.c)
-}
\ No newline at end of file
+	if h.cfs != nil {
+		h.cfs.remove(h)
+	}
+	h.destroy()
+}
+
+func (h *ColumnFamilyHandle) destroy() {
+	if h.c != nil {
+		C.rocksdb_column_family_handle_destroy(h.c)
+		h.c = nil
+	}
+}
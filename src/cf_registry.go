@@ -0,0 +1,186 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"sort"
+	"sync"
+	"./errors"
+)
+
+// DefaultColumnFamilyName is the name of the column family every RocksDB
+// database has.
+const DefaultColumnFamilyName = "default"
+
+// cfRegistry tracks the column family handles of a DB by name, so that
+// they can be looked up with DB.ColumnFamily and are destroyed when the DB
+// is closed.
+type cfRegistry struct {
+	mu      sync.RWMutex
+	byName  map[string]*ColumnFamilyHandle
+	handles []*ColumnFamilyHandle
+
+	// createMu serializes the creation of column families by DB.Bucket.
+	createMu sync.Mutex
+}
+
+func (r *cfRegistry) add(name string, h *ColumnFamilyHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.byName == nil {
+		r.byName = make(map[string]*ColumnFamilyHandle)
+	}
+	r.byName[name] = h
+	r.handles = append(r.handles, h)
+	h.cfs = r
+}
+
+// forget removes the handle from the names, it is still destroyed along
+// with the DB.
+func (r *cfRegistry) forget(h *ColumnFamilyHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, rh := range r.byName {
+		if rh == h {
+			delete(r.byName, name)
+		}
+	}
+}
+
+// remove removes the handle from the registry, for handles destroyed
+// before the DB is closed.
+func (r *cfRegistry) remove(h *ColumnFamilyHandle) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, rh := range r.byName {
+		if rh == h {
+			delete(r.byName, name)
+		}
+	}
+	for i, rh := range r.handles {
+		if rh == h {
+			r.handles = append(r.handles[:i], r.handles[i+1:]...)
+			break
+		}
+	}
+	h.cfs = nil
+}
+
+func (r *cfRegistry) get(name string) *ColumnFamilyHandle {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.byName[name]
+}
+
+func (r *cfRegistry) names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.byName))
+	for name := range r.byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (r *cfRegistry) destroy() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, h := range r.handles {
+		h.cfs = nil
+		h.destroy()
+	}
+	r.byName = nil
+	r.handles = nil
+}
+
+// ColumnFamily returns the handle of the named column family, or nil if
+// the DB holds no handle for it. The DB knows the column families it was
+// opened with and the ones created through it; the handles stay valid
+// until the DB is closed, which destroys them.
+func (db *DB) ColumnFamily(name string) *ColumnFamilyHandle {
+	return db.cfs.get(name)
+}
+
+// ColumnFamilyNames returns the sorted names of the column families the DB
+// holds a handle for.
+func (db *DB) ColumnFamilyNames() []string {
+	return db.cfs.names()
+}
+
+// OpenDbDiscoverColumnFamilies opens a database with all of its column
+// families, as listed by ListColumnFamilies, so that none has to be known
+// in advance. Each family is opened with its entry in cfOpts, or with opts
+// if it has none. If createMissing is true, the families of cfOpts the
+// database does not have yet are created; otherwise they are an error.
+//
+// The handles are available through DB.ColumnFamily.
+func OpenDbDiscoverColumnFamilies(opts *Options, name string, cfOpts map[string]*Options, createMissing bool) (*DB, error) {
+	names, err := existingColumnFamilies(opts, name)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(names))
+	openOpts := make([]*Options, len(names))
+	for i, n := range names {
+		known[n] = true
+		openOpts[i] = opts
+		if o, ok := cfOpts[n]; ok {
+			openOpts[i] = o
+		}
+	}
+	var missing []string
+	for n := range cfOpts {
+		if !known[n] {
+			missing = append(missing, n)
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) > 0 && !createMissing {
+		return nil, errors.FromStatus("Invalid argument: Column family not found: " + missing[0])
+	}
+
+	db, _, err := OpenDbColumnFamilies(opts, name, names, openOpts)
+	if err != nil {
+		return nil, err
+	}
+	for _, n := range missing {
+		if _, err := db.CreateColumnFamily(cfOpts[n], n); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// openAllColumnFamilies opens the database at name with all of its column
+// families, each with opts.
+func openAllColumnFamilies(opts *Options, name string, readOnly bool) (db *DB, err error) {
+	names, err := existingColumnFamilies(opts, name)
+	if err != nil {
+		return nil, err
+	}
+	cfOpts := make([]*Options, len(names))
+	for i := range cfOpts {
+		cfOpts[i] = opts
+	}
+	if readOnly {
+		db, _, err = OpenDbForReadOnlyColumnFamilies(opts, name, names, cfOpts, false)
+	} else {
+		db, _, err = OpenDbColumnFamilies(opts, name, names, cfOpts)
+	}
+	return db, err
+}
+
+// existingColumnFamilies returns the column families of the database at
+// name, which is only the default one for a database yet to be created.
+// Listing fails with an IO error when the database does not exist; any
+// other cause of such an error is reported again by the open that follows.
+func existingColumnFamilies(opts *Options, name string) ([]string, error) {
+	names, err := ListColumnFamilies(opts, name)
+	if errors.Is(err, errors.ErrIOError) {
+		return []string{DefaultColumnFamilyName}, nil
+	}
+	return names, err
+}
@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
@@ -5,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	. "./constants"
 )
 
 func TestColumnFamilyOpen(t *testing.T) {
@@ -80,7 +84,7 @@ func TestColumnFamilyBatchPutGet(t *testing.T) {
 	b0 := NewWriteBatch()
 	defer b0.Destroy()
 	b0.PutCF(cfh[0], givenKey0, givenVal0)
-	ensure.Nil(t, db.Write(wo, b0))
+	ensure.Nil(t, db.Write((*Batch)(b0), nil))
 	actualVal0, err := db.GetCF(ro, cfh[0], givenKey0)
 	defer actualVal0.Free()
 	ensure.Nil(t, err)
@@ -89,18 +93,16 @@ func TestColumnFamilyBatchPutGet(t *testing.T) {
 	b1 := NewWriteBatch()
 	defer b1.Destroy()
 	b1.PutCF(cfh[1], givenKey1, givenVal1)
-	ensure.Nil(t, db.Write(wo, b1))
+	ensure.Nil(t, db.Write((*Batch)(b1), nil))
 	actualVal1, err := db.GetCF(ro, cfh[1], givenKey1)
 	defer actualVal1.Free()
 	ensure.Nil(t, err)
 	ensure.DeepEqual(t, actualVal1.Data(), givenVal1)
 
-	actualVal, err := db.GetCF(ro, cfh[0], givenKey1)
-	ensure.Nil(t, err)
-	ensure.DeepEqual(t, actualVal.Size(), 0)
-	actualVal, err = db.GetCF(ro, cfh[1], givenKey0)
-	ensure.Nil(t, err)
-	ensure.DeepEqual(t, actualVal.Size(), 0)
+	_, err = db.GetCF(ro, cfh[0], givenKey1)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	_, err = db.GetCF(ro, cfh[1], givenKey0)
+	ensure.DeepEqual(t, err, ErrNotFound)
 }
 
 func TestColumnFamilyPutGetDelete(t *testing.T) {
@@ -140,15 +142,60 @@ func TestColumnFamilyPutGetDelete(t *testing.T) {
 	ensure.Nil(t, err)
 	ensure.DeepEqual(t, actualVal1.Data(), givenVal1)
 
-	actualVal, err := db.GetCF(ro, cfh[0], givenKey1)
+	_, err = db.GetCF(ro, cfh[0], givenKey1)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	_, err = db.GetCF(ro, cfh[1], givenKey0)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	ensure.Nil(t, db.DeleteCF(wo, cfh[0], givenKey0))
+	_, err = db.GetCF(ro, cfh[0], givenKey0)
+	ensure.DeepEqual(t, err, ErrNotFound)
+}
+
+func TestColumnFamilyRegistry(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName + "-TestColumnFamilyRegistry")
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, actualVal.Size(), 0)
-	actualVal, err = db.GetCF(ro, cfh[1], givenKey0)
+
+	opts := NewDefaultOptions()
+	opts.SetCreateIfMissing(true)
+	guideOpts := NewDefaultOptions()
+	db, err := OpenDbDiscoverColumnFamilies(opts, dir, map[string]*Options{"guide": guideOpts}, true)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, actualVal.Size(), 0)
+	ensure.DeepEqual(t, db.ColumnFamilyNames(), []string{"default", "guide"})
+	guide := db.ColumnFamily("guide")
+	ensure.NotNil(t, guide)
+	ensure.DeepEqual(t, guide.Name(), "guide")
+	ensure.DeepEqual(t, db.ColumnFamily("default").ID(), uint32(0))
+	ensure.True(t, guide.ID() != 0)
+	ensure.True(t, db.ColumnFamily("other") == nil)
 
-	ensure.Nil(t, db.DeleteCF(wo, cfh[0], givenKey0))
-	actualVal, err = db.GetCF(ro, cfh[0], givenKey0)
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	ensure.Nil(t, db.PutCF(wo, guide, []byte("key"), []byte("value")))
+	other, err := db.CreateColumnFamily(opts, "other")
+	ensure.Nil(t, err)
+	ensure.True(t, db.ColumnFamily("other") == other)
+	ensure.Nil(t, db.DropColumnFamily(other))
+	ensure.True(t, db.ColumnFamily("other") == nil)
+	other.Destroy()
+
+	// A handle destroyed before the DB is closed is forgotten by it.
+	guide.Destroy()
+	ensure.True(t, db.ColumnFamily("guide") == nil)
+	ensure.DeepEqual(t, db.ColumnFamilyNames(), []string{"default"})
+	ensure.Nil(t, db.Close())
+
+	// The families are discovered on reopen, and missing ones are an error
+	// unless they are to be created.
+	_, err = OpenDbDiscoverColumnFamilies(opts, dir, map[string]*Options{"other": opts}, false)
+	ensure.NotNil(t, err)
+	db, err = OpenDbDiscoverColumnFamilies(opts, dir, nil, false)
+	ensure.Nil(t, err)
+	defer db.Close()
+	ensure.DeepEqual(t, db.ColumnFamilyNames(), []string{"default", "guide"})
+	ro := NewDefaultReadOptions()
+	defer ro.Destroy()
+	value, err := db.GetCF(ro, db.ColumnFamily("guide"), []byte("key"))
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, actualVal.Size(), 0)
+	ensure.DeepEqual(t, value.Data(), []byte("value"))
 }
@@ -5,7 +5,7 @@ package rocksdb
 import "C"
 
 import (
-	"errors"
+	"./errors"
 	"unsafe"
 )
 
@@ -44,7 +44,7 @@ func (checkpoint *Checkpoint) CreateCheckpoint(checkpoint_dir string, log_size_f
 	C.rocksdb_checkpoint_create(checkpoint.c, cDir, C.uint64_t(log_size_for_flush), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
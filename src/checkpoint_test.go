@@ -1,7 +1,11 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
 	"github.com/facebookgo/ensure"
+	"./opt"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -22,9 +26,9 @@ func TestCheckpoint(t *testing.T) {
 	// insert keys
 	givenKeys := [][]byte{[]byte("key1"), []byte("key2"), []byte("key3")}
 	givenVal := []byte("val")
-	wo := NewDefaultWriteOptions()
+	wo := &opt.WriteOptions{}
 	for _, k := range givenKeys {
-		ensure.Nil(t, db.Put(wo, k, givenVal))
+		ensure.Nil(t, db.Put(k, givenVal, wo))
 	}
 
 	var dbCheck *DB
@@ -45,13 +49,12 @@ func TestCheckpoint(t *testing.T) {
 	ensure.Nil(t, err)
 
 	// test keys
-	var value *Slice
-	ro := NewDefaultReadOptions()
+	var value []byte
+	ro := &opt.ReadOptions{}
 	for _, k := range givenKeys {
-		value, err = dbCheck.Get(ro, k)
-		defer value.Free()
+		value, err = dbCheck.Get(k, ro)
 		ensure.Nil(t, err)
-		ensure.DeepEqual(t, value.Data(), givenVal)
+		ensure.DeepEqual(t, value, givenVal)
 	}
 
 }
@@ -62,7 +62,7 @@ func itf_compactionfilter_filter(idx int, cLevel C.int, cKey *C.char, cKeyLen C.
 	if remove {
 		return C.int(1)
 	} else if newVal != nil {
-		*cNewVal = /*ByteToChar(newVal)*/(*C.char)(unsafe.Pointer(&newVal[0]))
+		*cNewVal = byteToChar(newVal)
 		*cNewValLen = C.size_t(len(newVal))
 		*cValChanged = C.uchar(1)
 	}
@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
@@ -5,7 +8,9 @@ import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
-	"./constans"
+	"./opt"
+	"./util"
+	. "./constants"
 )
 
 func TestCompactionFilter(t *testing.T) {
@@ -14,6 +19,7 @@ func TestCompactionFilter(t *testing.T) {
 		changeValOld = []byte("old")
 		changeValNew = []byte("new")
 		deleteKey    = []byte("delete")
+		emptyKey     = []byte("empty")
 	)
 	db := newTestDB(t, "TestCompactionFilter", func(opts *Options) {
 		opts.SetCompactionFilter(&mockCompactionFilter{
@@ -24,6 +30,9 @@ func TestCompactionFilter(t *testing.T) {
 				if bytes.Equal(key, deleteKey) {
 					return true, val
 				}
+				if bytes.Equal(key, emptyKey) {
+					return false, []byte{}
+				}
 				t.Errorf("key %q not expected during compaction", key)
 				return false, nil
 			},
@@ -32,24 +41,28 @@ func TestCompactionFilter(t *testing.T) {
 	defer db.Close()
 
 	// insert the test keys
-	wo := NewDefaultWriteOptions()
-	ensure.Nil(t, db.Put(wo, changeKey, changeValOld))
-	ensure.Nil(t, db.Put(wo, deleteKey, changeValNew))
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put(changeKey, changeValOld, wo))
+	ensure.Nil(t, db.Put(deleteKey, changeValNew, wo))
+	ensure.Nil(t, db.Put(emptyKey, changeValOld, wo))
 
 	// trigger a compaction
-	db.CompactRange(Range{nil, nil})
+	db.CompactRange(util.Range{})
 
 	// ensure that the value is changed after compaction
-	ro := NewDefaultReadOptions()
-	v1, err := db.Get(ro, changeKey)
-	defer v1.Free()
+	ro := &opt.ReadOptions{}
+	v1, err := db.Get(changeKey, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v1.Data(), changeValNew)
+	ensure.DeepEqual(t, v1, changeValNew)
 
-	// ensure that the key is deleted after compaction
-	v2, err := db.Get(ro, deleteKey)
+	// ensure that the value can be changed to an empty one
+	v2, err := db.Get(emptyKey, ro)
 	ensure.Nil(t, err)
-	ensure.True(t, v2.Data() == nil)
+	ensure.DeepEqual(t, len(v2), 0)
+
+	// ensure that the key is deleted after compaction
+	_, err = db.Get(deleteKey, ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
 }
 
 type mockCompactionFilter struct {
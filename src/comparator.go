@@ -4,10 +4,15 @@ package rocksdb
 import "C"
 import (
 	"unsafe"
+
+	"./comparer"
 )
 
 // A Comparator object provides a total order across slices that are
 // used as keys in an sstable or a database.
+//
+// A Comparator that also implements comparer.Comparer has its Separator
+// and Successor methods used to shorten the keys stored in index blocks.
 type Comparator interface {
 	// Three-way comparison. Returns value:
 	//   < 0 iff "a" < "b",
@@ -54,3 +59,25 @@ func itf_comparator_compare(idx int, cKeyA *C.char, cKeyALen C.size_t, cKeyB *C.
 func itf_comparator_name(idx int) *C.char {
 	return comperators.Get(idx).(comperatorWrapper).name
 }
+
+//export itf_comparator_separator
+func itf_comparator_separator(idx int, cStart *C.char, cStartLen C.size_t, cLimit *C.char, cLimitLen C.size_t, cDstLen *C.size_t) *C.char {
+	cmp, ok := comperators.Get(idx).(comperatorWrapper).comparator.(comparer.Comparer)
+	if !ok {
+		return nil
+	}
+	dst := cmp.Separator(nil, charToByte(cStart, cStartLen), charToByte(cLimit, cLimitLen))
+	*cDstLen = C.size_t(len(dst))
+	return cByteSlice(dst)
+}
+
+//export itf_comparator_successor
+func itf_comparator_successor(idx int, cKey *C.char, cKeyLen C.size_t, cDstLen *C.size_t) *C.char {
+	cmp, ok := comperators.Get(idx).(comperatorWrapper).comparator.(comparer.Comparer)
+	if !ok {
+		return nil
+	}
+	dst := cmp.Successor(nil, charToByte(cKey, cKeyLen))
+	*cDstLen = C.size_t(len(dst))
+	return cByteSlice(dst)
+}
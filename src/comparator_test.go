@@ -1,11 +1,18 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
 	"bytes"
+	"fmt"
+	"io/ioutil"
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	"./comparer"
 	. "./constants"
+	"./opt"
 )
 
 func TestComparator(t *testing.T) {
@@ -16,14 +23,14 @@ func TestComparator(t *testing.T) {
 
 	// insert keys
 	givenKeys := [][]byte{[]byte("key1"), []byte("key2"), []byte("key3")}
-	wo := NewDefaultWriteOptions()
+	wo := &opt.WriteOptions{}
 	for _, k := range givenKeys {
-		ensure.Nil(t, db.Put(wo, k, []byte("val")))
+		ensure.Nil(t, db.Put(k, []byte("val"), wo))
 	}
 
 	// create a iterator to collect the keys
-	ro := NewDefaultReadOptions()
-	iter := db.NewIterator(ro)
+	ro := &opt.ReadOptions{}
+	iter := db.NewIterator(nil, ro)
 	defer iter.Close()
 
 	// we seek to the last key and iterate in reverse order
@@ -31,13 +38,53 @@ func TestComparator(t *testing.T) {
 	var actualKeys [][]byte
 	for iter.SeekToLast(); iter.Valid(); iter.Prev() {
 		key := make([]byte, 4)
-		copy(key, iter.Key().Data())
+		copy(key, iter.Key())
 		actualKeys = append(actualKeys, key)
 	}
 	ensure.Nil(t, iter.Err())
 
 	// ensure that the order is correct
 	ensure.DeepEqual(t, actualKeys, givenKeys)
+
+	// the DB compares keys in Go with the same comparator
+	ensure.True(t, db.compare([]byte("key2"), []byte("key1")) < 0)
+}
+
+func TestComparerSeparator(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestComparerSeparator")
+	ensure.Nil(t, err)
+
+	cmp := &countingComparer{Comparer: comparer.DefaultComparer}
+	db, err := OpenFile(dir, &opt.Options{Comparer: cmp, BlockSize: 256})
+	ensure.Nil(t, err)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	for i := 0; i < 100; i++ {
+		key := []byte(fmt.Sprintf("key%03d", i))
+		ensure.Nil(t, db.Put(key, bytes.Repeat([]byte("v"), 64), wo))
+	}
+	// flush to build a table with several data blocks
+	ensure.Nil(t, db.Flush(NewDefaultFlushOptions()))
+	ensure.True(t, cmp.separator > 0)
+
+	ro := &opt.ReadOptions{}
+	for i := 0; i < 100; i++ {
+		_, err := db.Get([]byte(fmt.Sprintf("key%03d", i)), ro)
+		ensure.Nil(t, err)
+	}
+}
+
+// countingComparer renames a comparer and counts the calls to Separator.
+type countingComparer struct {
+	comparer.Comparer
+	separator int
+}
+
+func (cmp *countingComparer) Name() string { return PkgName + ".counting" }
+func (cmp *countingComparer) Separator(dst, a, b []byte) []byte {
+	cmp.separator++
+	return cmp.Comparer.Separator(dst, a, b)
 }
 
 type bytesReverseComparator struct{}
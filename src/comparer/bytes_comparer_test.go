@@ -0,0 +1,28 @@
+package comparer
+
+import (
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestBytesComparerSeparator(t *testing.T) {
+	cases := []struct {
+		a, b, x []byte
+	}{
+		{[]byte("abc1xyz"), []byte("abc3"), []byte("abc2")},
+		{[]byte("abc"), []byte("abcd"), nil},
+		{[]byte("abc"), []byte("abd"), nil},
+		{[]byte{'a', 0xff}, []byte("b"), nil},
+	}
+	for _, c := range cases {
+		ensure.DeepEqual(t, DefaultComparer.Separator(nil, c.a, c.b), c.x)
+	}
+}
+
+func TestBytesComparerSuccessor(t *testing.T) {
+	ensure.DeepEqual(t, DefaultComparer.Successor(nil, []byte("abc")), []byte("b"))
+	ensure.DeepEqual(t, DefaultComparer.Successor(nil, []byte{0xff, 'a'}), []byte{0xff, 'b'})
+	ensure.DeepEqual(t, DefaultComparer.Successor(nil, []byte{0xff, 0xff}), []byte(nil))
+	ensure.DeepEqual(t, DefaultComparer.Successor([]byte("x"), []byte("a")), []byte("xb"))
+}
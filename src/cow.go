@@ -35,6 +35,18 @@ func (c *COWList) Append(i interface{}) int {
 	return newLen - 1
 }
 
+// Delete removes the item at index. The indices of the other items are
+// left unchanged, and Get returns nil for index afterwards.
+func (c *COWList) Delete(index int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	list := c.v.Load().([]interface{})
+	newList := make([]interface{}, len(list))
+	copy(newList, list)
+	newList[index] = nil
+	c.v.Store(newList)
+}
+
 // Get gets the item at index.
 func (c *COWList) Get(index int) interface{} {
 	list := c.v.Load().([]interface{})
@@ -18,6 +18,16 @@ func TestCOWList(t *testing.T) {
 	ensure.DeepEqual(t, cl.Get(2), "!")
 }
 
+func TestCOWListDelete(t *testing.T) {
+	cl := NewCOWList()
+	cl.Append("hello")
+	cl.Append("world")
+	cl.Delete(0)
+	ensure.Nil(t, cl.Get(0))
+	ensure.DeepEqual(t, cl.Get(1), "world")
+	ensure.DeepEqual(t, cl.Append("!"), 2)
+}
+
 func TestCOWListMT(t *testing.T) {
 	cl := NewCOWList()
 	expectedRes := make([]int, 3)
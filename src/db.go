@@ -4,10 +4,14 @@ package rocksdb
 //#include <stdlib.h>
 import "C"
 import (
-	"errors"
+	"io"
+	"runtime"
+	"sync/atomic"
 	"unsafe"
+	"./errors"
 	"./opt"
 	"./iterator"
+	"./storage"
 	. "./util"
 )
 
@@ -16,6 +20,31 @@ type DB struct {
 	c    *C.rocksdb_t
 	name string
 	opts *Options
+
+	// o holds the goleveldb style options set by OpenDB, it is nil for
+	// DBs opened with native Options.
+	o         *opt.Options
+	optsCache optionsCache
+
+	// readOnly is set for DBs opened read-only and secondary instances,
+	// whose mutating methods fail with ErrReadOnly. secondary is set for
+	// the latter, which follow a primary.
+	readOnly  bool
+	secondary bool
+	catchUp   catchUp
+
+	// cfs holds the column family handles the DB was opened with or
+	// created, they are destroyed on Close.
+	cfs cfRegistry
+
+	// storLock and closer are set when the DB is opened on a storage.
+	storLock storage.Locker
+	closer   io.Closer
+
+	// ownOpts is set when opts was created by OpenDB and is destroyed
+	// on Close.
+	ownOpts bool
+	closed  uint32
 }
 
 // OpenDb opens a database with the specified options.
@@ -28,7 +57,7 @@ func OpenDb(opts *Options, name string) (*DB, error) {
 	db := C.rocksdb_open(opts.c, cName, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 	return &DB{
 		name: name,
@@ -37,17 +66,63 @@ func OpenDb(opts *Options, name string) (*DB, error) {
 	}, nil
 }
 
-func OpenFile(p string, o *opt.Options) (db *DB, err error) {
-	opts := NewDefaultOptions()
-  rateLimiter := NewRateLimiter(int64(o.BlockCacheCapacity), int64(o.WriteBuffer), int32(o.Filter))
-  opts.SetTableCacheRemoveScanCountLimit(o.OpenFilesCacheCapacity)
-	opts.SetRateLimiter(rateLimiter)
-  opts.SetCreateIfMissing(true)
-  opts.SetSkipLogErrorOnRecovery(false)
-  return OpenDb(opts, p)
+// OpenDB opens or creates a DB for the given storage, translating the
+// goleveldb style options into the equivalent RocksDB configuration.
+// The DB will be created if not exist, unless ErrorIfMissing is true.
+// Also, if ErrorIfExist is true and the DB exist OpenDB will returns
+// an error. If ReadOnly is true, or the storage is read-only, the DB is
+// opened for read-only access.
+//
+// All the column families of the DB are opened, and can be reached by
+// name with DB.ColumnFamily or DB.Bucket.
+//
+// OpenDB holds the storage lock until the DB is closed, so the storage
+// can only be used by one DB at a time. Closing the DB does not close
+// the storage.
+func OpenDB(stor storage.Storage, o *opt.Options) (db *DB, err error) {
+	lock, err := stor.Lock()
+	if err != nil {
+		return nil, err
+	}
+	opts := newOptionsFromOpt(o)
+	if env := stor.Env(); env != nil {
+		opts.SetEnv(NewNativeEnv((*C.rocksdb_env_t)(env)))
+	}
+	db, err = openAllColumnFamilies(opts, stor.Path(), o.GetReadOnly() || stor.ReadOnly())
+	if err != nil {
+		opts.Destroy()
+		lock.Unlock()
+		return nil, err
+	}
+	if o != nil {
+		oc := *o
+		db.o = &oc
+	}
+	db.ownOpts = true
+	db.storLock = lock
+	return db, nil
+}
+
+// OpenFile opens or creates a DB for the given path, see OpenDB.
+// OpenFile uses a file-system backed storage, locking the path so
+// any subsequent attempt to open it fails with storage.ErrLocked
+// until the DB is closed.
+func OpenFile(path string, o *opt.Options) (db *DB, err error) {
+	stor, err := storage.OpenFile(path, o.GetReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	db, err = OpenDB(stor, o)
+	if err != nil {
+		stor.Close()
+		return nil, err
+	}
+	db.closer = stor
+	return db, nil
 }
 
 // OpenDbForReadOnly opens a database with the specified options for readonly usage.
+// The methods that would modify the DB fail with ErrReadOnly.
 func OpenDbForReadOnly(opts *Options, name string, errorIfLogFileExist bool) (*DB, error) {
 	var (
 		cErr  *C.char
@@ -57,16 +132,19 @@ func OpenDbForReadOnly(opts *Options, name string, errorIfLogFileExist bool) (*D
 	db := C.rocksdb_open_for_read_only(opts.c, cName, boolToChar(errorIfLogFileExist), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 	return &DB{
-		name: name,
-		c:    db,
-		opts: opts,
+		name:     name,
+		c:        db,
+		opts:     opts,
+		readOnly: true,
 	}, nil
 }
 
 // OpenDbColumnFamilies opens a database with the specified column families.
+// The handles are returned in the order of cfNames, and are also available
+// by name through DB.ColumnFamily.
 func OpenDbColumnFamilies(
 	opts *Options,
 	name string,
@@ -110,23 +188,24 @@ func OpenDbColumnFamilies(
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, nil, errors.New(C.GoString(cErr))
+		return nil, nil, errors.FromStatus(C.GoString(cErr))
 	}
 
 	cfHandles := make([]*ColumnFamilyHandle, numColumnFamilies)
-	for i, c := range cHandles {
-		cfHandles[i] = NewNativeColumnFamilyHandle(c)
-	}
-
-	return &DB{
+	d := &DB{
 		name: name,
 		c:    db,
 		opts: opts,
-	}, cfHandles, nil
+	}
+	for i, c := range cHandles {
+		cfHandles[i] = NewNativeColumnFamilyHandle(c)
+		d.cfs.add(cfNames[i], cfHandles[i])
+	}
+	return d, cfHandles, nil
 }
 
 // OpenDbForReadOnlyColumnFamilies opens a database with the specified column
-// families in read only mode.
+// families in read only mode, see OpenDbForReadOnly.
 func OpenDbForReadOnlyColumnFamilies(
 	opts *Options,
 	name string,
@@ -172,19 +251,21 @@ func OpenDbForReadOnlyColumnFamilies(
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, nil, errors.New(C.GoString(cErr))
+		return nil, nil, errors.FromStatus(C.GoString(cErr))
 	}
 
 	cfHandles := make([]*ColumnFamilyHandle, numColumnFamilies)
+	d := &DB{
+		name:     name,
+		c:        db,
+		opts:     opts,
+		readOnly: true,
+	}
 	for i, c := range cHandles {
 		cfHandles[i] = NewNativeColumnFamilyHandle(c)
+		d.cfs.add(cfNames[i], cfHandles[i])
 	}
-
-	return &DB{
-		name: name,
-		c:    db,
-		opts: opts,
-	}, cfHandles, nil
+	return d, cfHandles, nil
 }
 
 // ListColumnFamilies lists the names of the column families in the DB.
@@ -198,7 +279,7 @@ func ListColumnFamilies(opts *Options, name string) ([]string, error) {
 	cNames := C.rocksdb_list_column_families(opts.c, cName, &cLen, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 	namesLen := int(cLen)
 	names := make([]string, namesLen)
@@ -220,8 +301,24 @@ func (db *DB) Name() string {
 	return db.name
 }
 
+// IsReadOnly reports whether the DB was opened read-only, or as a
+// secondary instance. The methods that would modify such a DB fail with
+// ErrReadOnly.
+func (db *DB) IsReadOnly() bool {
+	return db.readOnly
+}
+
+// writable returns ErrReadOnly if the DB cannot be written to.
+func (db *DB) writable() error {
+	if db.readOnly {
+		return errors.ErrReadOnly
+	}
+	return nil
+}
+
 // Get returns the data associated with the key from the database.
-func (db *DB) Get(/*opts *ReadOptions, */key []byte, opts *ReadOptions) (/**Slice*/[]byte, error) {/*
+// It returns ErrNotFound if the DB does not contain the key.
+func (db *DB) Get(/*opts *ReadOptions, */key []byte, ro *opt.ReadOptions) (/**Slice*/[]byte, error) {/*
 	var (
 		cErr    *C.char
 		cValLen C.size_t
@@ -230,115 +327,212 @@ func (db *DB) Get(/*opts *ReadOptions, */key []byte, opts *ReadOptions) (/**Slic
 	cValue := C.rocksdb_get(db.c, opts.c, cKey, C.size_t(len(key)), &cValLen, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 	return NewSlice(cValue, cValLen), nil*/
-	return db.GetBytes(opts, key)
+	return db.GetBytes(db.readOptions(ro), key)
 }
 
 // GetBytes is like Get but returns a copy of the data.
+// It returns ErrNotFound if the DB does not contain the key.
 func (db *DB) GetBytes(opts *ReadOptions, key []byte) ([]byte, error) {
+	var (
+		cErr *C.char
+		cKey = byteToChar(key)
+	)
+	cPinned := C.rocksdb_get_pinned(db.c, opts.c, cKey, C.size_t(len(key)), &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
+	}
+	if cPinned == nil {
+		return nil, errors.ErrNotFound
+	}
+	defer C.rocksdb_pinnableslice_destroy(cPinned)
+	var cValLen C.size_t
+	cValue := C.rocksdb_pinnableslice_value(cPinned, &cValLen)
+	return C.GoBytes(unsafe.Pointer(cValue), C.int(cValLen)), nil
+}
+
+// Has returns true if the DB does contain the given key. A negative answer
+// from KeyMayExist is trusted as is, so absent keys are usually resolved
+// without touching the disk; the value is never copied to Go memory.
+func (db *DB) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	return db.has(key, db.readOptions(ro))
+}
+
+func (db *DB) has(key []byte, opts *ReadOptions) (bool, error) {
 	var (
 		cErr    *C.char
+		cValue  *C.char
 		cValLen C.size_t
-		cKey    = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
+		cFound  C.uchar
+		cKey    = byteToChar(key)
 	)
-	cValue := C.rocksdb_get(db.c, opts.c, cKey, C.size_t(len(key)), &cValLen, &cErr)
+	if C.rocksdb_key_may_exist(db.c, opts.c, cKey, C.size_t(len(key)), &cValue, &cValLen, nil, 0, &cFound) == 0 {
+		return false, nil
+	}
+	if cFound != 0 {
+		C.free(unsafe.Pointer(cValue))
+		return true, nil
+	}
+	cPinned := C.rocksdb_get_pinned(db.c, opts.c, cKey, C.size_t(len(key)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return false, errors.FromStatus(C.GoString(cErr))
 	}
-	if cValue == nil {
-		return nil, nil
+	if cPinned == nil {
+		return false, nil
 	}
-	defer C.free(unsafe.Pointer(cValue))
-	return C.GoBytes(unsafe.Pointer(cValue), C.int(cValLen)), nil
+	C.rocksdb_pinnableslice_destroy(cPinned)
+	return true, nil
 }
 
 // GetCF returns the data associated with the key from the database and column family.
+// It returns ErrNotFound if the column family does not contain the key.
 func (db *DB) GetCF(opts *ReadOptions, cf *ColumnFamilyHandle, key []byte) (*Slice, error) {
 	var (
-		cErr    *C.char
-		cValLen C.size_t
-		cKey    = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
+		cErr *C.char
+		cKey = byteToChar(key)
 	)
-	cValue := C.rocksdb_get_cf(db.c, opts.c, cf.c, cKey, C.size_t(len(key)), &cValLen, &cErr)
+	cPinned := C.rocksdb_get_pinned_cf(db.c, opts.c, cf.c, cKey, C.size_t(len(key)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
+	}
+	if cPinned == nil {
+		return nil, errors.ErrNotFound
 	}
+	defer C.rocksdb_pinnableslice_destroy(cPinned)
+	var cValLen C.size_t
+	cValue := C.rocksdb_pinnableslice_value(cPinned, &cValLen)
 	return /*NewSlice(cValue, cValLen), nil*/StringToSlice(C.GoStringN(cValue, (C.int)(cValLen))), nil
 }
 
 // Put writes data associated with a key to the database.
-func (db *DB) Put(/*opts *WriteOptions, */key, value []byte, opts *WriteOptions) error {
+func (db *DB) Put(/*opts *WriteOptions, */key, value []byte, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var (
 		cErr   *C.char
-		cKey   = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
-		cValue = /*ByteToChar(value)*/(*C.char)(unsafe.Pointer(&value[0]))
+		cKey   = byteToChar(key)
+		cValue = byteToChar(value)
 	)
-	C.rocksdb_put(db.c, opts.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)), &cErr)
+	C.rocksdb_put(db.c, db.writeOptions(wo).c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // PutCF writes data associated with a key to the database and column family.
 func (db *DB) PutCF(opts *WriteOptions, cf *ColumnFamilyHandle, key, value []byte) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var (
 		cErr   *C.char
-		cKey   = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
-		cValue = /*ByteToChar(value)*/(*C.char)(unsafe.Pointer(&value[0]))
+		cKey   = byteToChar(key)
+		cValue = byteToChar(value)
 	)
 	C.rocksdb_put_cf(db.c, opts.c, cf.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // Delete removes the data associated with the key from the database.
-func (db *DB) Delete(/*opts *WriteOptions, */key []byte, opts *WriteOptions) error {
+func (db *DB) Delete(/*opts *WriteOptions, */key []byte, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var (
 		cErr *C.char
-		cKey = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
+		cKey = byteToChar(key)
 	)
-	C.rocksdb_delete(db.c, opts.c, cKey, C.size_t(len(key)), &cErr)
+	C.rocksdb_delete(db.c, db.writeOptions(wo).c, cKey, C.size_t(len(key)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // DeleteCF removes the data associated with the key from the database and column family.
 func (db *DB) DeleteCF(opts *WriteOptions, cf *ColumnFamilyHandle, key []byte) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var (
 		cErr *C.char
-		cKey = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
+		cKey = byteToChar(key)
 	)
 	C.rocksdb_delete_cf(db.c, opts.c, cf.c, cKey, C.size_t(len(key)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
+	}
+	return nil
+}
+
+// SingleDelete removes the data associated with the key from the database,
+// as Delete does, for a key that was put once and never overwritten or
+// merged. Its tombstone and the put it hides are both dropped as soon as
+// a compaction brings them together, rather than once the bottommost level
+// is reached. Using SingleDelete on a key written more than once leads to
+// undefined results.
+func (db *DB) SingleDelete(key []byte, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	var (
+		cErr *C.char
+		cKey = byteToChar(key)
+	)
+	C.rocksdb_singledelete(db.c, db.writeOptions(wo).c, cKey, C.size_t(len(key)), &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return errors.FromStatus(C.GoString(cErr))
+	}
+	return nil
+}
+
+// SingleDeleteCF is like SingleDelete but removes the key from the column
+// family.
+func (db *DB) SingleDeleteCF(opts *WriteOptions, cf *ColumnFamilyHandle, key []byte) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	var (
+		cErr *C.char
+		cKey = byteToChar(key)
+	)
+	C.rocksdb_singledelete_cf(db.c, opts.c, cf.c, cKey, C.size_t(len(key)), &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // Merge merges the data associated with the key with the actual data in the database.
 func (db *DB) Merge(opts *WriteOptions, key []byte, value []byte) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var (
 		cErr   *C.char
-		cKey   = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
-		cValue = /*ByteToChar(value)*/(*C.char)(unsafe.Pointer(&value[0]))
+		cKey   = byteToChar(key)
+		cValue = byteToChar(value)
 	)
 	C.rocksdb_merge(db.c, opts.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -346,35 +540,54 @@ func (db *DB) Merge(opts *WriteOptions, key []byte, value []byte) error {
 // MergeCF merges the data associated with the key with the actual data in the
 // database and column family.
 func (db *DB) MergeCF(opts *WriteOptions, cf *ColumnFamilyHandle, key []byte, value []byte) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var (
 		cErr   *C.char
-		cKey   = /*ByteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
-		cValue = /*ByteToChar(value)*/(*C.char)(unsafe.Pointer(&value[0]))
+		cKey   = byteToChar(key)
+		cValue = byteToChar(value)
 	)
 	C.rocksdb_merge_cf(db.c, opts.c, cf.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // Write writes a WriteBatch to the database
-func (db *DB) Write(/*opts *WriteOptions, batch *WriteBatch*/batch *Batch, opts *WriteOptions) error {
+func (db *DB) Write(/*opts *WriteOptions, batch *WriteBatch*/batch *Batch, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var cErr *C.char
-	C.rocksdb_write(db.c, opts.c, batch.c, &cErr)
+	batch.init()
+	C.rocksdb_write(db.c, db.writeOptions(wo).c, batch.c, &cErr)
+	runtime.KeepAlive(batch)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // NewIterator returns an Iterator over the the database that uses the
-// ReadOptions given.
-func (db *DB) NewIterator(slice *Range, opts *ReadOptions) *iterator.Iterator {
-	cIter := C.rocksdb_create_iterator(db.c, opts.c)
-	return iterator.NewNativeIterator(unsafe.Pointer(cIter))
+// ReadOptions given. The iterator is limited to the keys within slice,
+// which are applied as the iterate lower and upper bounds; a nil slice
+// or a nil Limit leaves that side unbounded.
+func (db *DB) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	return db.newIterator(slice, db.readOptions(ro))
+}
+
+func (db *DB) newIterator(slice *Range, opts *ReadOptions) *iterator.Iterator {
+	if slice == nil || (len(slice.Start) == 0 && slice.Limit == nil) {
+		cIter := C.rocksdb_create_iterator(db.c, opts.c)
+		return iterator.NewNativeIterator(unsafe.Pointer(cIter))
+	}
+	ro, free := opts.withBounds(slice)
+	cIter := C.rocksdb_create_iterator(db.c, ro.c)
+	return iterator.NewNativeIteratorWithCleanup(unsafe.Pointer(cIter), free)
 }
 
 // NewIteratorCF returns an Iterator over the the database and column family
@@ -387,35 +600,48 @@ func (db *DB) NewIteratorCF(opts *ReadOptions, cf *ColumnFamilyHandle) *iterator
 // NewSnapshot creates a new snapshot of the database.
 func (db *DB) NewSnapshot() *Snapshot {
 	cSnap := C.rocksdb_create_snapshot(db.c)
-	return NewNativeSnapshot(cSnap)
+	snap := NewNativeSnapshot(cSnap)
+	snap.db = db
+	return snap
 }
 
 // ReleaseSnapshot releases the snapshot and its resources.
 func (db *DB) ReleaseSnapshot(snapshot *Snapshot) {
-	C.rocksdb_release_snapshot(db.c, snapshot.c)
-	snapshot.c = nil
+	snapshot.release(db)
 }
 
-// GetProperty returns the value of a database property.
+// GetProperty returns the value of a database property, see the Property
+// constants. It fails if the property is unknown.
 func (db *DB) GetProperty(propName string) (string, error) {
 	cprop := C.CString(propName)
 	defer C.free(unsafe.Pointer(cprop))
 	cValue := C.rocksdb_property_value(db.c, cprop)
+	if cValue == nil {
+		return "", errUnknownProperty(propName)
+	}
 	defer C.free(unsafe.Pointer(cValue))
 	return C.GoString(cValue), nil
 }
 
-// GetPropertyCF returns the value of a database property.
-func (db *DB) GetPropertyCF(propName string, cf *ColumnFamilyHandle) string {
+// GetPropertyCF returns the value of a database property of the column
+// family. It fails if the property is unknown.
+func (db *DB) GetPropertyCF(propName string, cf *ColumnFamilyHandle) (string, error) {
 	cProp := C.CString(propName)
 	defer C.free(unsafe.Pointer(cProp))
 	cValue := C.rocksdb_property_value_cf(db.c, cf.c, cProp)
+	if cValue == nil {
+		return "", errUnknownProperty(propName)
+	}
 	defer C.free(unsafe.Pointer(cValue))
-	return C.GoString(cValue)
+	return C.GoString(cValue), nil
 }
 
-// CreateColumnFamily create a new column family.
+// CreateColumnFamily create a new column family. The handle is destroyed
+// when the DB is closed.
 func (db *DB) CreateColumnFamily(opts *Options, name string) (*ColumnFamilyHandle, error) {
+	if err := db.writable(); err != nil {
+		return nil, err
+	}
 	var (
 		cErr  *C.char
 		cName = C.CString(name)
@@ -424,19 +650,26 @@ func (db *DB) CreateColumnFamily(opts *Options, name string) (*ColumnFamilyHandl
 	cHandle := C.rocksdb_create_column_family(db.c, opts.c, cName, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
-	return NewNativeColumnFamilyHandle(cHandle), nil
+	h := NewNativeColumnFamilyHandle(cHandle)
+	db.cfs.add(name, h)
+	return h, nil
 }
 
-// DropColumnFamily drops a column family.
+// DropColumnFamily drops a column family. The handle stays valid until it
+// is destroyed, but DB.ColumnFamily no longer returns it.
 func (db *DB) DropColumnFamily(c *ColumnFamilyHandle) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var cErr *C.char
 	C.rocksdb_drop_column_family(db.c, c.c, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
+	db.cfs.forget(c)
 	return nil
 }
 
@@ -455,12 +688,20 @@ func (db *DB) GetApproximateSizes(ranges []Range) []uint64 {
 	cLimits := make([]*C.char, len(ranges))
 	cStartLens := make([]C.size_t, len(ranges))
 	cLimitLens := make([]C.size_t, len(ranges))
+	// The keys are copied to C memory, as cgo does not allow passing Go
+	// pointers stored in Go memory.
 	for i, r := range ranges {
-		cStarts[i] = /*ByteToChar(r.Start)*/(*C.char)(unsafe.Pointer(&r.Start[0]))
+		cStarts[i] = cByteSlice(r.Start)
 		cStartLens[i] = C.size_t(len(r.Start))
-		cLimits[i] = /*ByteToChar(r.Limit)*/(*C.char)(unsafe.Pointer(&r.Limit[0]))
+		cLimits[i] = cByteSlice(r.Limit)
 		cLimitLens[i] = C.size_t(len(r.Limit))
 	}
+	defer func() {
+		for i := range ranges {
+			C.free(unsafe.Pointer(cStarts[i]))
+			C.free(unsafe.Pointer(cLimits[i]))
+		}
+	}()
 
 	C.rocksdb_approximate_sizes(
 		db.c,
@@ -489,12 +730,20 @@ func (db *DB) GetApproximateSizesCF(cf *ColumnFamilyHandle, ranges []Range) []ui
 	cLimits := make([]*C.char, len(ranges))
 	cStartLens := make([]C.size_t, len(ranges))
 	cLimitLens := make([]C.size_t, len(ranges))
+	// The keys are copied to C memory, as cgo does not allow passing Go
+	// pointers stored in Go memory.
 	for i, r := range ranges {
-		cStarts[i] = /*ByteToChar(r.Start)*/(*C.char)(unsafe.Pointer(&r.Start[0]))
+		cStarts[i] = cByteSlice(r.Start)
 		cStartLens[i] = C.size_t(len(r.Start))
-		cLimits[i] = /*ByteToChar(r.Limit)*/(*C.char)(unsafe.Pointer(&r.Limit[0]))
+		cLimits[i] = cByteSlice(r.Limit)
 		cLimitLens[i] = C.size_t(len(r.Limit))
 	}
+	defer func() {
+		for i := range ranges {
+			C.free(unsafe.Pointer(cStarts[i]))
+			C.free(unsafe.Pointer(cLimits[i]))
+		}
+	}()
 
 	C.rocksdb_approximate_sizes_cf(
 		db.c,
@@ -546,27 +795,37 @@ func (db *DB) GetLiveFilesMetaData() []LiveFileMetadata {
 // CompactRange runs a manual compaction on the Range of keys given. This is
 // not likely to be needed for typical usage.
 func (db *DB) CompactRange(r Range) error {
-	cStart := /*ByteToChar(r.Start)*/(*C.char)(unsafe.Pointer(&r.Start[0]))
-	cLimit := /*ByteToChar(r.Limit)*/(*C.char)(unsafe.Pointer(&r.Limit[0]))
+	if err := db.writable(); err != nil {
+		return err
+	}
+	cStart := byteToChar(r.Start)
+	cLimit := byteToChar(r.Limit)
   C.rocksdb_compact_range(db.c, cStart, C.size_t(len(r.Start)), cLimit, C.size_t(len(r.Limit)))
   return nil
 }
 
 // CompactRangeCF runs a manual compaction on the Range of keys given on the
 // given column family. This is not likely to be needed for typical usage.
-func (db *DB) CompactRangeCF(cf *ColumnFamilyHandle, r Range) {
-	cStart := /*ByteToChar(r.Start)*/(*C.char)(unsafe.Pointer(&r.Start[0]))
-	cLimit := /*ByteToChar(r.Limit)*/(*C.char)(unsafe.Pointer(&r.Limit[0]))
+func (db *DB) CompactRangeCF(cf *ColumnFamilyHandle, r Range) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	cStart := byteToChar(r.Start)
+	cLimit := byteToChar(r.Limit)
 	C.rocksdb_compact_range_cf(db.c, cf.c, cStart, C.size_t(len(r.Start)), cLimit, C.size_t(len(r.Limit)))
+	return nil
 }
 
 // Flush triggers a manuel flush for the database.
 func (db *DB) Flush(opts *FlushOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	var cErr *C.char
 	C.rocksdb_flush(db.c, opts.c, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -577,7 +836,7 @@ func (db *DB) DisableFileDeletions() error {
 	C.rocksdb_disable_file_deletions(db.c, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -588,7 +847,7 @@ func (db *DB) EnableFileDeletions(force bool) error {
 	C.rocksdb_enable_file_deletions(db.c, boolToChar(force), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -596,14 +855,21 @@ func (db *DB) EnableFileDeletions(force bool) error {
 // DeleteFile deletes the file name from the db directory and update the internal state to
 // reflect that. Supports deletion of sst and log files only. 'name' must be
 // path relative to the db directory. eg. 000001.sst, /archive/000003.log.
-func (db *DB) DeleteFile(name string) {
+func (db *DB) DeleteFile(name string) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	cName := C.CString(name)
 	defer C.free(unsafe.Pointer(cName))
 	C.rocksdb_delete_file(db.c, cName)
+	return nil
 }
 
 // IngestExternalFile loads a list of external SST files.
 func (db *DB) IngestExternalFile(filePaths []string, opts *IngestExternalFileOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	cFilePaths := make([]*C.char, len(filePaths))
 	for i, s := range filePaths {
 		cFilePaths[i] = C.CString(s)
@@ -626,13 +892,16 @@ func (db *DB) IngestExternalFile(filePaths []string, opts *IngestExternalFileOpt
 
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // IngestExternalFileCF loads a list of external SST files for a column family.
 func (db *DB) IngestExternalFileCF(handle *ColumnFamilyHandle, filePaths []string, opts *IngestExternalFileOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
 	cFilePaths := make([]*C.char, len(filePaths))
 	for i, s := range filePaths {
 		cFilePaths[i] = C.CString(s)
@@ -656,7 +925,7 @@ func (db *DB) IngestExternalFileCF(handle *ColumnFamilyHandle, filePaths []strin
 
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -671,15 +940,32 @@ func (db *DB) NewCheckpoint() (*Checkpoint, error) {
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 
 	return NewNativeCheckpoint(cCheckpoint), nil
 }
 
-// Close closes the database.
+// Close closes the database. The column family handles the DB knows of
+// are destroyed first. Closing a closed DB returns ErrClosed.
 func (db *DB) Close() error {
+  if !atomic.CompareAndSwapUint32(&db.closed, 0, 1) {
+    return errors.ErrClosed
+  }
+  db.StopCatchUp()
+  db.cfs.destroy()
   C.rocksdb_close(db.c)
+  db.c = nil
+  db.optsCache.destroy()
+  if db.ownOpts {
+    db.opts.Destroy()
+  }
+  if db.storLock != nil {
+    db.storLock.Unlock()
+  }
+  if db.closer != nil {
+    return db.closer.Close()
+  }
   return nil
 }
 
@@ -694,7 +980,7 @@ func DestroyDb(name string, opts *Options) error {
 	C.rocksdb_destroy_db(opts.c, cName, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -709,7 +995,7 @@ func RepairDb(name string, opts *Options) error {
 	C.rocksdb_repair_db(opts.c, cName, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
\ No newline at end of file
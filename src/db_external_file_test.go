@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
@@ -6,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	"./opt"
 )
 
 func TestExternalFile(t *testing.T) {
@@ -40,18 +44,18 @@ func TestExternalFile(t *testing.T) {
 	err = db.IngestExternalFile([]string{filePath.Name()}, ingestOpts)
 	ensure.Nil(t, err)
 
-	readOpts := NewDefaultReadOptions()
+	readOpts := &opt.ReadOptions{}
 
-	v1, err := db.Get(readOpts, []byte("aaa"))
+	v1, err := db.Get([]byte("aaa"), readOpts)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v1.Data(), []byte("aaaValue"))
-	v2, err := db.Get(readOpts, []byte("bbb"))
+	ensure.DeepEqual(t, v1, []byte("aaaValue"))
+	v2, err := db.Get([]byte("bbb"), readOpts)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v2.Data(), []byte("bbbValue"))
-	v3, err := db.Get(readOpts, []byte("ccc"))
+	ensure.DeepEqual(t, v2, []byte("bbbValue"))
+	v3, err := db.Get([]byte("ccc"), readOpts)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v3.Data(), []byte("cccValue"))
-	v4, err := db.Get(readOpts, []byte("ddd"))
+	ensure.DeepEqual(t, v3, []byte("cccValue"))
+	v4, err := db.Get([]byte("ddd"), readOpts)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v4.Data(), []byte("dddValue"))
+	ensure.DeepEqual(t, v4, []byte("dddValue"))
 }
@@ -0,0 +1,80 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"sync"
+
+	"./opt"
+)
+
+// readOptionsKey identifies the native ReadOptions an opt.ReadOptions
+// is translated into.
+type readOptionsKey struct {
+	fillCache       bool
+	verifyChecksums bool
+}
+
+// optionsCache caches the native options the goleveldb style options of a
+// DB are translated into, so that reads and writes need no C allocations.
+type optionsCache struct {
+	mu  sync.Mutex
+	ros map[readOptionsKey]*ReadOptions
+	wos map[bool]*WriteOptions
+}
+
+// readOptions returns the native ReadOptions for ro, nil meaning the
+// defaults. They are owned by the DB and must not be modified or destroyed.
+func (db *DB) readOptions(ro *opt.ReadOptions) *ReadOptions {
+	key := readOptionsKey{
+		fillCache:       !ro.GetDontFillCache(),
+		verifyChecksums: opt.GetStrict(db.o, ro, opt.StrictBlockChecksum),
+	}
+	c := &db.optsCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if opts, ok := c.ros[key]; ok {
+		return opts
+	}
+	opts := NewDefaultReadOptions()
+	opts.SetFillCache(key.fillCache)
+	opts.SetVerifyChecksums(key.verifyChecksums)
+	if c.ros == nil {
+		c.ros = make(map[readOptionsKey]*ReadOptions)
+	}
+	c.ros[key] = opts
+	return opts
+}
+
+// writeOptions returns the native WriteOptions for wo, nil meaning the
+// defaults. They are owned by the DB and must not be modified or destroyed.
+func (db *DB) writeOptions(wo *opt.WriteOptions) *WriteOptions {
+	sync := wo.GetSync() && !db.o.GetNoSync()
+	c := &db.optsCache
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if opts, ok := c.wos[sync]; ok {
+		return opts
+	}
+	opts := NewDefaultWriteOptions()
+	opts.SetSync(sync)
+	if c.wos == nil {
+		c.wos = make(map[bool]*WriteOptions)
+	}
+	c.wos[sync] = opts
+	return opts
+}
+
+// destroy deallocates the cached options.
+func (c *optionsCache) destroy() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, opts := range c.ros {
+		opts.Destroy()
+	}
+	for _, opts := range c.wos {
+		opts.Destroy()
+	}
+	c.ros, c.wos = nil, nil
+}
@@ -0,0 +1,80 @@
+package rocksdb
+
+//#include "api.h"
+//#include <stdlib.h>
+import "C"
+import (
+	"unsafe"
+	"./errors"
+)
+
+// errUnknownProperty is returned for properties RocksDB does not know, or
+// that cannot be read with the type asked for.
+func errUnknownProperty(propName string) error {
+	return errors.FromStatus("Not implemented: unknown property: " + propName)
+}
+
+// GetIntProperty returns the value of an integer database property, see
+// the Property constants. It fails if the property is unknown or not an
+// integer.
+func (db *DB) GetIntProperty(propName string) (uint64, error) {
+	var cValue C.uint64_t
+	cProp := C.CString(propName)
+	defer C.free(unsafe.Pointer(cProp))
+	if C.rocksdb_property_int(db.c, cProp, &cValue) != 0 {
+		return 0, errUnknownProperty(propName)
+	}
+	return uint64(cValue), nil
+}
+
+// GetIntPropertyCF returns the value of an integer database property of
+// the column family.
+func (db *DB) GetIntPropertyCF(propName string, cf *ColumnFamilyHandle) (uint64, error) {
+	var cValue C.uint64_t
+	cProp := C.CString(propName)
+	defer C.free(unsafe.Pointer(cProp))
+	if C.rocksdb_property_int_cf(db.c, cf.c, cProp, &cValue) != 0 {
+		return 0, errUnknownProperty(propName)
+	}
+	return uint64(cValue), nil
+}
+
+// GetMapProperty returns the value of a database property as a map, such
+// as the one of PropertyCFStats. It fails if the property is unknown or
+// has no map form.
+func (db *DB) GetMapProperty(propName string) (map[string]string, error) {
+	return db.getMapProperty(propName, nil)
+}
+
+// GetMapPropertyCF returns the value of a database property of the column
+// family as a map.
+func (db *DB) GetMapPropertyCF(propName string, cf *ColumnFamilyHandle) (map[string]string, error) {
+	return db.getMapProperty(propName, cf)
+}
+
+// getMapProperty reads the map property propName of cf, nil meaning the
+// default column family.
+func (db *DB) getMapProperty(propName string, cf *ColumnFamilyHandle) (map[string]string, error) {
+	var (
+		cCF  *C.rocksdb_column_family_handle_t
+		cKVs **C.char
+		cLen C.size_t
+	)
+	if cf != nil {
+		cCF = cf.c
+	}
+	cProp := C.CString(propName)
+	defer C.free(unsafe.Pointer(cProp))
+	if C.api_property_map(db.c, cCF, cProp, &cKVs, &cLen) == 0 {
+		return nil, errUnknownProperty(propName)
+	}
+	defer C.free(unsafe.Pointer(cKVs))
+	kvs := charSlice(cKVs, C.int(2*cLen))
+	props := make(map[string]string, int(cLen))
+	for i := 0; i < len(kvs); i += 2 {
+		props[C.GoString(kvs[i])] = C.GoString(kvs[i+1])
+		C.free(unsafe.Pointer(kvs[i]))
+		C.free(unsafe.Pointer(kvs[i+1]))
+	}
+	return props, nil
+}
@@ -0,0 +1,166 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"./errors"
+	. "./util"
+)
+
+// Stats populates s with database statistics, read from the
+// rocksdb.levelstats, rocksdb.cfstats and rocksdb.dbstats properties of
+// the default column family. See DBStats for how RocksDB statistics map
+// onto the goleveldb fields.
+func (db *DB) Stats(s *DBStats) error {
+	s.reset()
+
+	levelStats, err := db.GetProperty(PropertyLevelStats)
+	if err != nil {
+		return err
+	}
+	if s.LevelTablesCounts, err = parseLevelStats(levelStats); err != nil {
+		return err
+	}
+	cfStats, err := db.GetMapProperty(PropertyCFStats)
+	if err != nil {
+		return err
+	}
+	stat := func(level, name string) float64 {
+		v, _ := strconv.ParseFloat(cfStats["compaction."+level+"."+name], 64)
+		return v
+	}
+	for i := range s.LevelTablesCounts {
+		level := "L" + strconv.Itoa(i)
+		s.LevelSizes = append(s.LevelSizes, int64(stat(level, "SizeBytes")))
+		s.LevelRead = append(s.LevelRead, int64(stat(level, "ReadGB")*(1<<30)))
+		s.LevelWrite = append(s.LevelWrite, int64(stat(level, "WriteGB")*(1<<30)))
+		s.LevelDurations = append(s.LevelDurations, time.Duration(stat(level, "CompSec")*float64(time.Second)))
+
+		comps := uint32(stat(level, "CompCount"))
+		switch i {
+		case 0:
+			s.MemComp = comps
+		case 1:
+			s.Level0Comp = comps
+		default:
+			s.NonLevel0Comp += comps
+		}
+	}
+	s.IORead = uint64(s.LevelRead.Sum())
+	s.IOWrite = uint64(s.LevelWrite.Sum())
+
+	slowdowns, _ := strconv.ParseFloat(cfStats["io_stalls.total_slowdown"], 64)
+	stops, _ := strconv.ParseFloat(cfStats["io_stalls.total_stop"], 64)
+	s.WriteDelayCount = int32(slowdowns + stops)
+
+	dbStats, err := db.GetProperty(PropertyDBStats)
+	if err != nil {
+		return err
+	}
+	s.WriteDelayDuration = parseCumulativeStall(dbStats)
+
+	if v, err := db.GetIntProperty(PropertyIsWriteStopped); err == nil {
+		s.WritePaused = v != 0
+	}
+	if v, err := db.GetIntProperty(PropertyNumSnapshots); err == nil {
+		s.AliveSnapshots = int32(v)
+	}
+	if v, err := db.GetIntProperty(PropertyBlockCacheUsage); err == nil {
+		s.BlockCacheSize = int(v)
+	}
+	if v, err := db.GetIntProperty(PropertyCurSizeAllMemTables); err == nil {
+		s.MemTableSize = int(v)
+	}
+	return nil
+}
+
+// parseLevelStats returns the number of files of each level from the
+// rocksdb.levelstats property, which looks like:
+//
+//      Level Files Size(MB)
+//      --------------------
+//        0        2        0
+//        1        0        0
+func parseLevelStats(levelStats string) ([]int, error) {
+	var counts []int
+	sc := bufio.NewScanner(strings.NewReader(levelStats))
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 3 {
+			continue
+		}
+		level, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		files, err := strconv.Atoi(fields[1])
+		if err != nil || level != len(counts) {
+			return nil, errors.FromStatus("Corruption: malformed rocksdb.levelstats: " + sc.Text())
+		}
+		counts = append(counts, files)
+	}
+	return counts, nil
+}
+
+// parseCumulativeStall returns the cumulative write stall duration from
+// the "Cumulative stall: 00:00:0.000 H:M:S, 0.0 percent" line of the
+// rocksdb.dbstats property.
+func parseCumulativeStall(dbStats string) time.Duration {
+	const prefix = "Cumulative stall:"
+	sc := bufio.NewScanner(strings.NewReader(dbStats))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if !strings.HasPrefix(line, prefix) {
+			continue
+		}
+		var (
+			h, m int
+			sec  float64
+		)
+		if _, err := fmt.Sscanf(line[len(prefix):], "%d:%d:%f", &h, &m, &sec); err != nil {
+			return 0
+		}
+		return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(math.Round(sec*1000))*time.Millisecond
+	}
+	return 0
+}
+
+// SizeOf calculates approximate sizes of the given key ranges, from the
+// tables of the default column family. As with goleveldb, a range whose
+// Limit is not greater than its Start, such as one with a nil Limit, has
+// size zero.
+func (db *DB) SizeOf(ranges []Range) (Sizes, error) {
+	var valid []Range
+	for _, r := range ranges {
+		if db.compare(r.Start, r.Limit) < 0 {
+			valid = append(valid, r)
+		}
+	}
+	approx := db.GetApproximateSizes(valid)
+	sizes := make(Sizes, len(ranges))
+	for i, r := range ranges {
+		if db.compare(r.Start, r.Limit) < 0 {
+			sizes[i] = int64(approx[0])
+			approx = approx[1:]
+		}
+	}
+	return sizes, nil
+}
+
+// compare orders keys as the comparator the DB was opened with does. A
+// native comparator cannot be called from Go, so it is taken to be the
+// bytewise comparator of RocksDB, like the default.
+func (db *DB) compare(a, b []byte) int {
+	if db.opts != nil && db.opts.cmp != nil {
+		return db.opts.cmp.Compare(a, b)
+	}
+	return bytes.Compare(a, b)
+}
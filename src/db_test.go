@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
@@ -6,7 +9,13 @@ import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
-	"./constants"
+	. "./constants"
+	"./errors"
+	"./filter"
+	"./iterator"
+	"./opt"
+	"./storage"
+	"./util"
 )
 
 func TestOpenDb(t *testing.T) {
@@ -22,31 +31,28 @@ func TestDBCRUD(t *testing.T) {
 		givenKey  = []byte("hello")
 		givenVal1 = []byte("world1")
 		givenVal2 = []byte("world2")
-		wo        = NewDefaultWriteOptions()
-		ro        = NewDefaultReadOptions()
+		wo        = &opt.WriteOptions{}
+		ro        = &opt.ReadOptions{}
 	)
 
 	// create
-	ensure.Nil(t, db.Put(wo, givenKey, givenVal1))
+	ensure.Nil(t, db.Put(givenKey, givenVal1, wo))
 
 	// retrieve
-	v1, err := db.Get(ro, givenKey)
-	defer v1.Free()
+	v1, err := db.Get(givenKey, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v1.Data(), givenVal1)
+	ensure.DeepEqual(t, v1, givenVal1)
 
 	// update
-	ensure.Nil(t, db.Put(wo, givenKey, givenVal2))
-	v2, err := db.Get(ro, givenKey)
-	defer v2.Free()
+	ensure.Nil(t, db.Put(givenKey, givenVal2, wo))
+	v2, err := db.Get(givenKey, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v2.Data(), givenVal2)
+	ensure.DeepEqual(t, v2, givenVal2)
 
 	// delete
-	ensure.Nil(t, db.Delete(wo, givenKey))
-	v3, err := db.Get(ro, givenKey)
-	ensure.Nil(t, err)
-	ensure.True(t, v3.Data() == nil)
+	ensure.Nil(t, db.Delete(givenKey, wo))
+	_, err = db.Get(givenKey, ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
 }
 
 func TestDBCRUDDBPaths(t *testing.T) {
@@ -65,31 +71,28 @@ func TestDBCRUDDBPaths(t *testing.T) {
 		givenKey  = []byte("hello")
 		givenVal1 = []byte("world1")
 		givenVal2 = []byte("world2")
-		wo        = NewDefaultWriteOptions()
-		ro        = NewDefaultReadOptions()
+		wo        = &opt.WriteOptions{}
+		ro        = &opt.ReadOptions{}
 	)
 
 	// create
-	ensure.Nil(t, db.Put(wo, givenKey, givenVal1))
+	ensure.Nil(t, db.Put(givenKey, givenVal1, wo))
 
 	// retrieve
-	v1, err := db.Get(ro, givenKey)
-	defer v1.Free()
+	v1, err := db.Get(givenKey, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v1.Data(), givenVal1)
+	ensure.DeepEqual(t, v1, givenVal1)
 
 	// update
-	ensure.Nil(t, db.Put(wo, givenKey, givenVal2))
-	v2, err := db.Get(ro, givenKey)
-	defer v2.Free()
+	ensure.Nil(t, db.Put(givenKey, givenVal2, wo))
+	v2, err := db.Get(givenKey, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v2.Data(), givenVal2)
+	ensure.DeepEqual(t, v2, givenVal2)
 
 	// delete
-	ensure.Nil(t, db.Delete(wo, givenKey))
-	v3, err := db.Get(ro, givenKey)
-	ensure.Nil(t, err)
-	ensure.True(t, v3.Data() == nil)
+	ensure.Nil(t, db.Delete(givenKey, wo))
+	_, err = db.Get(givenKey, ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
 }
 
 func newTestDB(t *testing.T, name string, applyOpts func(opts *Options)) *DB {
@@ -141,3 +144,564 @@ func newTestDBPathNames(t *testing.T, name string, names []string, target_sizes
 
 	return db
 }
+
+func TestOpenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestOpenFile")
+	ensure.Nil(t, err)
+
+	o := &opt.Options{
+		BlockCacheCapacity:     16 * opt.MiB,
+		WriteBuffer:            8 * opt.MiB,
+		Filter:                 filter.NewBloomFilter(10),
+		OpenFilesCacheCapacity: 100,
+		Compression:            opt.NoCompression,
+	}
+	db, err := OpenFile(dir, o)
+	ensure.Nil(t, err)
+
+	wo := &opt.WriteOptions{}
+	ro := &opt.ReadOptions{}
+	ensure.Nil(t, db.Put([]byte("hello"), []byte("world"), wo))
+	v, err := db.Get([]byte("hello"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("world"))
+	ensure.Nil(t, db.Close())
+	ensure.DeepEqual(t, db.Close(), errors.ErrClosed)
+
+	// the database exists now
+	_, err = OpenFile(dir, &opt.Options{ErrorIfExist: true})
+	ensure.NotNil(t, err)
+
+	db, err = OpenFile(dir, &opt.Options{ReadOnly: true})
+	ensure.Nil(t, err)
+	defer db.Close()
+	ensure.True(t, db.IsReadOnly())
+	ensure.DeepEqual(t, db.Put([]byte("hello"), []byte("again"), nil), errors.ErrReadOnly)
+	v, err = db.Get([]byte("hello"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("world"))
+}
+
+func TestOpenFileErrorIfMissing(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestOpenFileErrorIfMissing")
+	ensure.Nil(t, err)
+
+	_, err = OpenFile(dir, &opt.Options{ErrorIfMissing: true})
+	ensure.NotNil(t, err)
+}
+
+func TestOpenFileLocked(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestOpenFileLocked")
+	ensure.Nil(t, err)
+
+	db, err := OpenFile(dir, nil)
+	ensure.Nil(t, err)
+
+	_, err = OpenFile(dir, nil)
+	ensure.DeepEqual(t, err, storage.ErrLocked)
+
+	// the lock is released once the DB is closed
+	ensure.Nil(t, db.Close())
+	db, err = OpenFile(dir, nil)
+	ensure.Nil(t, err)
+	ensure.Nil(t, db.Close())
+}
+
+func TestOpenDBMemStorage(t *testing.T) {
+	stor := storage.NewMemStorage()
+	defer stor.Close()
+
+	db, err := OpenDB(stor, nil)
+	ensure.Nil(t, err)
+
+	_, err = OpenDB(stor, nil)
+	ensure.DeepEqual(t, err, storage.ErrLocked)
+
+	wo := &opt.WriteOptions{}
+	ro := &opt.ReadOptions{}
+	ensure.Nil(t, db.Put([]byte("hello"), []byte("world"), wo))
+	ensure.Nil(t, db.Close())
+
+	// the contents survive reopening the same storage
+	db, err = OpenDB(stor, &opt.Options{ErrorIfMissing: true})
+	ensure.Nil(t, err)
+	defer db.Close()
+	v, err := db.Get([]byte("hello"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("world"))
+}
+
+func TestDBOptionsTranslation(t *testing.T) {
+	db := newTestDB(t, "TestDBOptionsTranslation", nil)
+	defer db.Close()
+
+	// nil means the defaults
+	ensure.Nil(t, db.Put([]byte("hello"), []byte("world"), nil))
+	ensure.Nil(t, db.Put([]byte("synced"), []byte("world"), &opt.WriteOptions{Sync: true}))
+	v, err := db.Get([]byte("hello"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("world"))
+	v, err = db.Get([]byte("synced"), &opt.ReadOptions{DontFillCache: true})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("world"))
+
+	// the native options are cached
+	ensure.True(t, db.readOptions(nil) == db.readOptions(&opt.ReadOptions{}))
+	ensure.False(t, db.readOptions(nil) == db.readOptions(&opt.ReadOptions{DontFillCache: true}))
+	ensure.True(t, db.writeOptions(nil) == db.writeOptions(&opt.WriteOptions{NoWriteMerge: true}))
+	ensure.False(t, db.writeOptions(nil) == db.writeOptions(&opt.WriteOptions{Sync: true}))
+}
+
+func TestOpenFileNoSync(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestOpenFileNoSync")
+	ensure.Nil(t, err)
+
+	db, err := OpenFile(dir, &opt.Options{NoSync: true})
+	ensure.Nil(t, err)
+	defer db.Close()
+
+	ensure.True(t, db.writeOptions(nil) == db.writeOptions(&opt.WriteOptions{Sync: true}))
+	ensure.Nil(t, db.Put([]byte("hello"), []byte("world"), &opt.WriteOptions{Sync: true}))
+}
+
+func TestDBNewIteratorRange(t *testing.T) {
+	db := newTestDB(t, "TestDBNewIteratorRange", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	for _, k := range []string{"a1", "b1", "b2", "b3", "c1"} {
+		ensure.Nil(t, db.Put([]byte(k), []byte("val"), wo))
+	}
+
+	collect := func(r *util.Range) []string {
+		ro := &opt.ReadOptions{}
+		iter := db.NewIterator(r, ro)
+		defer iter.Close()
+		var keys []string
+		for iter.SeekToFirst(); iter.Valid(); iter.Next() {
+			keys = append(keys, string(iter.Key()))
+		}
+		ensure.Nil(t, iter.Err())
+		return keys
+	}
+
+	ensure.DeepEqual(t, collect(util.BytesPrefix([]byte("b"))), []string{"b1", "b2", "b3"})
+	ensure.DeepEqual(t, collect(&util.Range{Start: []byte("b2")}), []string{"b2", "b3", "c1"})
+	ensure.DeepEqual(t, collect(&util.Range{Limit: []byte("b2")}), []string{"a1", "b1"})
+	ensure.DeepEqual(t, collect(nil), []string{"a1", "b1", "b2", "b3", "c1"})
+}
+
+func TestDBIteratorSemantics(t *testing.T) {
+	db := newTestDB(t, "TestDBIteratorSemantics", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	givenKeys := []string{"key1", "key2", "key3"}
+	for _, k := range givenKeys {
+		ensure.Nil(t, db.Put([]byte(k), []byte("val"), wo))
+	}
+
+	ro := &opt.ReadOptions{}
+	iter := db.NewIterator(nil, ro)
+
+	// a new iterator is not positioned
+	ensure.False(t, iter.Valid())
+	ensure.True(t, iter.Key() == nil)
+
+	var actualKeys []string
+	for iter.Next() {
+		actualKeys = append(actualKeys, string(iter.Key()))
+	}
+	ensure.Nil(t, iter.Error())
+	ensure.DeepEqual(t, actualKeys, givenKeys)
+
+	// an exhausted iterator restarts from the last key
+	ensure.True(t, iter.Prev())
+	ensure.DeepEqual(t, iter.Key(), []byte("key3"))
+
+	ensure.True(t, iter.Seek([]byte("key2")))
+	ensure.DeepEqual(t, iter.Key(), []byte("key2"))
+	ensure.False(t, iter.Seek([]byte("key4")))
+	ensure.True(t, iter.First())
+	ensure.DeepEqual(t, iter.Key(), []byte("key1"))
+	ensure.False(t, iter.Prev())
+	ensure.True(t, iter.Last())
+	ensure.DeepEqual(t, iter.Key(), []byte("key3"))
+
+	released := false
+	iter.SetReleaser(releaserFunc(func() { released = true }))
+	iter.Release()
+	iter.Release()
+	ensure.True(t, released)
+	ensure.False(t, iter.Next())
+	ensure.DeepEqual(t, iter.Error(), util.ErrIterReleased)
+}
+
+type releaserFunc func()
+
+func (f releaserFunc) Release() { f() }
+
+func TestDBHas(t *testing.T) {
+	db := newTestDB(t, "TestDBHas", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	ro := &opt.ReadOptions{}
+	ensure.Nil(t, db.Put([]byte("hello"), []byte("world"), wo))
+	ensure.Nil(t, db.Put([]byte("empty"), []byte{}, wo))
+
+	ok, err := db.Has([]byte("hello"), ro)
+	ensure.Nil(t, err)
+	ensure.True(t, ok)
+	ok, err = db.Has([]byte("missing"), ro)
+	ensure.Nil(t, err)
+	ensure.False(t, ok)
+
+	// an empty value is not a missing key
+	v, err := db.Get([]byte("empty"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, len(v), 0)
+	_, err = db.Get([]byte("missing"), ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
+}
+
+func TestDBStatsSizeOf(t *testing.T) {
+	db := newTestDB(t, "TestDBStatsSizeOf", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	for i := 0; i < 1000; i++ {
+		key := []byte("key" + strconv.Itoa(i))
+		ensure.Nil(t, db.Put(key, make([]byte, 100), wo))
+	}
+	fo := NewDefaultFlushOptions()
+	defer fo.Destroy()
+	fo.SetWait(true)
+	ensure.Nil(t, db.Flush(fo))
+
+	snap, err := db.GetSnapshot()
+	ensure.Nil(t, err)
+	defer snap.Release()
+
+	var stats DBStats
+	ensure.Nil(t, db.Stats(&stats))
+	ensure.True(t, len(stats.LevelTablesCounts) > 1)
+	ensure.DeepEqual(t, stats.LevelTablesCounts[0], 1)
+	ensure.DeepEqual(t, len(stats.LevelSizes), len(stats.LevelTablesCounts))
+	ensure.True(t, stats.LevelSizes[0] > 0)
+	ensure.DeepEqual(t, stats.MemComp, uint32(1))
+	ensure.DeepEqual(t, stats.AliveSnapshots, int32(1))
+
+	sizes, err := db.SizeOf([]util.Range{
+		{Start: []byte("key"), Limit: []byte("kez")},
+		{Start: []byte("key")},
+		{Start: []byte("a"), Limit: []byte("b")},
+	})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, len(sizes), 3)
+	ensure.True(t, sizes[0] > 0)
+	ensure.DeepEqual(t, sizes[1], int64(0))
+	ensure.DeepEqual(t, sizes.Sum(), sizes[0]+sizes[2])
+}
+
+func TestDBIteratorKeyValueCopies(t *testing.T) {
+	db := newTestDB(t, "TestDBIteratorKeyValueCopies", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put([]byte("key1"), []byte("val1"), wo))
+	ensure.Nil(t, db.Put([]byte("key2"), []byte("val2"), wo))
+
+	iter := db.NewIterator(nil, nil)
+	defer iter.Release()
+	ensure.True(t, iter.Next())
+	key, value := iter.Key(), iter.Value()
+	ensure.DeepEqual(t, iter.KeyUnsafe(), key)
+	ensure.DeepEqual(t, iter.ValueUnsafe(), value)
+
+	// the copies outlive the position they were taken at
+	ensure.True(t, iter.Next())
+	ensure.DeepEqual(t, key, []byte("key1"))
+	ensure.DeepEqual(t, value, []byte("val1"))
+	ensure.DeepEqual(t, iter.KeyUnsafe(), []byte("key2"))
+	ensure.DeepEqual(t, iter.ValueUnsafe(), []byte("val2"))
+
+	ensure.False(t, iter.Next())
+	ensure.True(t, iter.KeyUnsafe() == nil)
+	ensure.True(t, iter.ValueUnsafe() == nil)
+}
+
+// emptyKVCases are the key/value pairs with zero-length keys or values
+// that every entry point must accept.
+var emptyKVCases = []struct {
+	name       string
+	key, value []byte
+}{
+	{"empty value", []byte("key"), []byte{}},
+	{"nil value", []byte("key"), nil},
+	{"empty key", []byte{}, []byte("value")},
+	{"nil key", nil, []byte("value")},
+	{"empty key and value", []byte{}, []byte{}},
+}
+
+func TestDBEmptyKeysAndValues(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestDBEmptyKeysAndValues")
+	ensure.Nil(t, err)
+	opts := NewDefaultOptions()
+	opts.SetCreateIfMissing(true)
+	opts.SetCreateIfMissingColumnFamilies(true)
+	db, cfh, err := OpenDbColumnFamilies(opts, dir, []string{"default", "other"}, []*Options{opts, opts})
+	ensure.Nil(t, err)
+	defer db.Close()
+	defer cfh[1].Destroy()
+	defer cfh[0].Destroy()
+
+	wo := &opt.WriteOptions{}
+	nwo := NewDefaultWriteOptions()
+	defer nwo.Destroy()
+	nro := NewDefaultReadOptions()
+	defer nro.Destroy()
+
+	for _, c := range emptyKVCases {
+		ensure.Nil(t, db.Put(c.key, c.value, wo), c.name)
+		v, err := db.Get(c.key, nil)
+		ensure.Nil(t, err, c.name)
+		ensure.DeepEqual(t, string(v), string(c.value), c.name)
+		has, err := db.Has(c.key, nil)
+		ensure.Nil(t, err, c.name)
+		ensure.True(t, has, c.name)
+
+		iter := db.NewIterator(nil, nil)
+		ensure.True(t, iter.Seek(c.key), c.name)
+		ensure.DeepEqual(t, string(iter.Key()), string(c.key), c.name)
+		ensure.DeepEqual(t, string(iter.Value()), string(c.value), c.name)
+		ensure.True(t, iter.SeekForPrev(c.key), c.name)
+		ensure.DeepEqual(t, string(iter.Key()), string(c.key), c.name)
+		iter.Release()
+
+		ensure.Nil(t, db.Delete(c.key, wo), c.name)
+		_, err = db.Get(c.key, nil)
+		ensure.DeepEqual(t, err, ErrNotFound, c.name)
+
+		ensure.Nil(t, db.PutCF(nwo, cfh[1], c.key, c.value), c.name)
+		s, err := db.GetCF(nro, cfh[1], c.key)
+		ensure.Nil(t, err, c.name)
+		ensure.DeepEqual(t, string(s.Data()), string(c.value), c.name)
+		s.Free()
+		ensure.Nil(t, db.DeleteCF(nwo, cfh[1], c.key), c.name)
+		_, err = db.GetCF(nro, cfh[1], c.key)
+		ensure.DeepEqual(t, err, ErrNotFound, c.name)
+
+		b := NewBatch()
+		b.Put(c.key, c.value)
+		ensure.Nil(t, db.Write(b, wo), c.name)
+		b.Destroy()
+		v, err = db.Get(c.key, nil)
+		ensure.Nil(t, err, c.name)
+		ensure.DeepEqual(t, string(v), string(c.value), c.name)
+		ensure.Nil(t, db.Delete(c.key, wo), c.name)
+	}
+
+	// a nil Start and Limit compact the whole key space
+	ensure.Nil(t, db.CompactRange(util.Range{}))
+	ensure.Nil(t, db.CompactRangeCF(cfh[1], util.Range{}))
+}
+
+func TestDBMultiGet(t *testing.T) {
+	db := newTestDB(t, "TestDBMultiGet", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), wo))
+	ensure.Nil(t, db.Put([]byte("c"), []byte{}, wo))
+	keys := [][]byte{[]byte("a"), []byte("b"), []byte("c"), {}}
+
+	values, errs := db.MultiGet(nil, keys)
+	ensure.DeepEqual(t, values, [][]byte{[]byte("1"), nil, {}, nil})
+	ensure.DeepEqual(t, errs, []error{nil, ErrNotFound, nil, ErrNotFound})
+
+	cf, err := db.CreateColumnFamily(NewDefaultOptions(), "other")
+	ensure.Nil(t, err)
+	defer cf.Destroy()
+	nwo := NewDefaultWriteOptions()
+	defer nwo.Destroy()
+	nro := NewDefaultReadOptions()
+	defer nro.Destroy()
+	ensure.Nil(t, db.PutCF(nwo, cf, []byte("b"), []byte("2")))
+	values, errs = db.MultiGetCF(nro, cf, keys)
+	ensure.DeepEqual(t, values, [][]byte{nil, []byte("2"), nil, nil})
+	ensure.DeepEqual(t, errs, []error{ErrNotFound, nil, ErrNotFound, ErrNotFound})
+
+	values, errs = db.MultiGet(nil, nil)
+	ensure.DeepEqual(t, len(values), 0)
+	ensure.DeepEqual(t, len(errs), 0)
+}
+
+func dbKeys(t *testing.T, iter *iterator.Iterator) []string {
+	defer iter.Release()
+	keys := []string{}
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	ensure.Nil(t, iter.Err())
+	return keys
+}
+
+func TestDBDeleteRange(t *testing.T) {
+	db := newTestDB(t, "TestDBDeleteRange", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	for _, k := range []string{"a", "t1/a", "t1/b", "t2/a", "t2/b", "z"} {
+		ensure.Nil(t, db.Put([]byte(k), []byte("v"), wo))
+	}
+
+	ensure.Nil(t, db.DeleteRange(wo, util.Range{Start: []byte("t2/a"), Limit: []byte("t2/b")}))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIterator(nil, nil)), []string{"a", "t1/a", "t1/b", "t2/b", "z"})
+
+	ensure.Nil(t, db.DeletePrefix(wo, []byte("t1/"), true))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIterator(nil, nil)), []string{"a", "t2/b", "z"})
+
+	// A nil Limit reaches past the last key.
+	ensure.Nil(t, db.DeleteRange(wo, util.Range{Start: []byte("t")}))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIterator(nil, nil)), []string{"a"})
+	ensure.Nil(t, db.DeleteRange(wo, util.Range{Start: []byte("b")}))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIterator(nil, nil)), []string{"a"})
+
+	cf, err := db.CreateColumnFamily(NewDefaultOptions(), "other")
+	ensure.Nil(t, err)
+	defer cf.Destroy()
+	nwo := NewDefaultWriteOptions()
+	defer nwo.Destroy()
+	nro := NewDefaultReadOptions()
+	defer nro.Destroy()
+	wb := NewWriteBatch()
+	defer wb.Destroy()
+	for _, k := range []string{"a", "b", "c", "d"} {
+		wb.PutCF(cf, []byte(k), []byte("v"))
+	}
+	wb.DeleteRangeCF(cf, []byte("a"), []byte("b"))
+	ensure.Nil(t, db.Write((*Batch)(wb), wo))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIteratorCF(nro, cf)), []string{"b", "c", "d"})
+	ensure.Nil(t, db.DeleteRangeCF(nwo, cf, util.Range{Start: []byte("c")}))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIteratorCF(nro, cf)), []string{"b"})
+	ensure.DeepEqual(t, dbKeys(t, db.NewIterator(nil, nil)), []string{"a"})
+}
+
+func TestDBDeleteRangeComparator(t *testing.T) {
+	db := newTestDB(t, "TestDBDeleteRangeComparator", func(opts *Options) {
+		opts.SetComparator(&bytesReverseComparator{})
+	})
+	defer db.Close()
+
+	for _, k := range []string{"a", "b", "c"} {
+		ensure.Nil(t, db.Put([]byte(k), []byte("v"), nil))
+	}
+	// in reverse order the keys past "b" are "b" and "a"
+	ensure.Nil(t, db.DeleteRange(nil, util.Range{Start: []byte("b")}))
+	ensure.DeepEqual(t, dbKeys(t, db.NewIterator(nil, nil)), []string{"c"})
+}
+
+func TestDBSingleDelete(t *testing.T) {
+	db := newTestDB(t, "TestDBSingleDelete", nil)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	ensure.Nil(t, db.SingleDelete([]byte("a"), nil))
+	_, err := db.Get([]byte("a"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	cf, err := db.CreateColumnFamily(NewDefaultOptions(), "other")
+	ensure.Nil(t, err)
+	defer cf.Destroy()
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	ro := NewDefaultReadOptions()
+	defer ro.Destroy()
+	ensure.Nil(t, db.PutCF(wo, cf, []byte("b"), []byte("2")))
+	ensure.Nil(t, db.SingleDeleteCF(wo, cf, []byte("b")))
+	_, err = db.GetCF(ro, cf, []byte("b"))
+	ensure.DeepEqual(t, err, ErrNotFound)
+}
+
+func TestDBProperties(t *testing.T) {
+	db := newTestDB(t, "TestDBProperties", nil)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	n, err := db.GetIntProperty(PropertyEstimateNumKeys)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, n, uint64(1))
+	_, err = db.GetIntProperty(PropertyStats)
+	ensure.NotNil(t, err)
+	_, err = db.GetIntProperty("rocksdb.no-such-property")
+	ensure.True(t, errors.Is(err, errors.ErrNotSupported))
+
+	stats, err := db.GetProperty(PropertyStats)
+	ensure.Nil(t, err)
+	ensure.True(t, len(stats) > 0)
+	_, err = db.GetProperty("rocksdb.no-such-property")
+	ensure.NotNil(t, err)
+
+	cfStats, err := db.GetMapProperty(PropertyCFStats)
+	ensure.Nil(t, err)
+	ensure.True(t, len(cfStats) > 0)
+	_, err = db.GetMapProperty("rocksdb.no-such-property")
+	ensure.NotNil(t, err)
+
+	cf, err := db.CreateColumnFamily(NewDefaultOptions(), "other")
+	ensure.Nil(t, err)
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	ensure.Nil(t, db.PutCF(wo, cf, []byte("a"), []byte("1")))
+	ensure.Nil(t, db.PutCF(wo, cf, []byte("b"), []byte("2")))
+	n, err = db.GetIntPropertyCF(PropertyEstimateNumKeys, cf)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, n, uint64(2))
+	_, err = db.GetPropertyCF(PropertyCFStats, cf)
+	ensure.Nil(t, err)
+	_, err = db.GetPropertyCF("rocksdb.no-such-property", cf)
+	ensure.NotNil(t, err)
+	_, err = db.GetMapPropertyCF(PropertyCFStats, cf)
+	ensure.Nil(t, err)
+}
+
+func TestDBReadOnly(t *testing.T) {
+	db := newTestDB(t, "TestDBReadOnly", nil)
+	ensure.False(t, db.IsReadOnly())
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	ensure.Nil(t, db.Close())
+
+	opts := NewDefaultOptions()
+	db, err := OpenDbForReadOnly(opts, db.Name(), false)
+	ensure.Nil(t, err)
+	defer db.Close()
+	ensure.True(t, db.IsReadOnly())
+	v, err := db.Get([]byte("a"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("1"))
+
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	fo := NewDefaultFlushOptions()
+	defer fo.Destroy()
+	for _, err := range []error{
+		db.Put([]byte("a"), []byte("2"), nil),
+		db.Delete([]byte("a"), nil),
+		db.SingleDelete([]byte("a"), nil),
+		db.Merge(wo, []byte("a"), []byte("2")),
+		db.Write(NewBatch(), nil),
+		db.DeleteRange(nil, util.Range{Start: []byte("a")}),
+		db.CompactRange(util.Range{}),
+		db.Flush(fo),
+		db.DeleteFile("000001.sst"),
+	} {
+		ensure.DeepEqual(t, err, errors.ErrReadOnly)
+	}
+	_, err = db.CreateColumnFamily(opts, "other")
+	ensure.DeepEqual(t, err, errors.ErrReadOnly)
+	_, err = db.Bucket("other")
+	ensure.DeepEqual(t, err, errors.ErrReadOnly)
+	_, err = db.OpenTransaction()
+	ensure.DeepEqual(t, err, errors.ErrReadOnly)
+}
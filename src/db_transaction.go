@@ -0,0 +1,227 @@
+package rocksdb
+
+//#include "api.h"
+//#include <stdlib.h>
+import "C"
+import (
+	"sync"
+	"unsafe"
+	"./errors"
+	"./iterator"
+	"./opt"
+	. "./constants"
+	. "./util"
+)
+
+var (
+	errTransactionDone    = errors.New(PkgName + ": transaction already closed")
+	errBatchNotReplayable = errors.FromStatus("Not implemented: transactions only apply puts and deletions of the default column family")
+)
+
+// DBTransaction is the goleveldb style transaction handle of a DB, created
+// by DB.OpenTransaction. Writes are buffered in a RocksDB WriteBatchWithIndex,
+// are visible to reads through the transaction, and are written to the DB
+// atomically by Commit.
+//
+// Unlike goleveldb, an open transaction does not block other writers; it
+// provides read-your-writes and an atomic commit, not isolation.
+type DBTransaction struct {
+	c  *C.rocksdb_writebatch_wi_t
+	db *DB
+
+	mu    sync.Mutex
+	iters []*iterator.Iterator
+	done  bool
+}
+
+// OpenTransaction opens an atomic DB transaction. The returned transaction
+// must be closed, either by Commit or by Discard.
+func (db *DB) OpenTransaction() (*DBTransaction, error) {
+	if err := db.writable(); err != nil {
+		return nil, err
+	}
+	return &DBTransaction{
+		c:  C.rocksdb_writebatch_wi_create(0, boolToChar(true)),
+		db: db,
+	}, nil
+}
+
+// Get gets the value for the given key, looking at the pending writes of
+// the transaction first. It returns ErrNotFound if neither the transaction
+// nor the DB contains the key.
+func (tr *DBTransaction) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return nil, errTransactionDone
+	}
+	var (
+		cErr    *C.char
+		cValLen C.size_t
+		cKey    = byteToChar(key)
+	)
+	cValue := C.rocksdb_writebatch_wi_get_from_batch_and_db(tr.c, tr.db.c, tr.db.readOptions(ro).c, cKey, C.size_t(len(key)), &cValLen, &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
+	}
+	if cValue == nil {
+		return nil, errors.ErrNotFound
+	}
+	defer C.free(unsafe.Pointer(cValue))
+	return C.GoBytes(unsafe.Pointer(cValue), C.int(cValLen)), nil
+}
+
+// Has returns true if the transaction or the DB contains the given key.
+func (tr *DBTransaction) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	_, err := tr.Get(key, ro)
+	switch err {
+	case nil:
+		return true, nil
+	case errors.ErrNotFound:
+		return false, nil
+	}
+	return false, err
+}
+
+// Put sets the value for the given key within the transaction. wo is
+// accepted for goleveldb compatibility; Commit decides how the write is
+// persisted.
+func (tr *DBTransaction) Put(key, value []byte, wo *opt.WriteOptions) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	tr.put(key, value)
+	return nil
+}
+
+func (tr *DBTransaction) put(key, value []byte) {
+	cKey := byteToChar(key)
+	cValue := byteToChar(value)
+	C.rocksdb_writebatch_wi_put(tr.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)))
+}
+
+// Delete deletes the value for the given key within the transaction.
+func (tr *DBTransaction) Delete(key []byte, wo *opt.WriteOptions) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	tr.delete(key)
+	return nil
+}
+
+func (tr *DBTransaction) delete(key []byte) {
+	cKey := byteToChar(key)
+	C.rocksdb_writebatch_wi_delete(tr.c, cKey, C.size_t(len(key)))
+}
+
+// transactionReplay applies replayed batch records to a transaction.
+type transactionReplay struct {
+	tr *DBTransaction
+}
+
+func (r transactionReplay) Put(key, value []byte) { r.tr.put(key, value) }
+func (r transactionReplay) Delete(key []byte)     { r.tr.delete(key) }
+
+// Write applies the puts and deletes of the given batch to the transaction.
+// Batches holding other records, such as merges, range deletions or
+// updates of other column families, are rejected as a whole with
+// ErrNotSupported.
+func (tr *DBTransaction) Write(batch *Batch, wo *opt.WriteOptions) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	if batch.native() {
+		ok, err := replayable((*WriteBatch)(batch).NewIterator())
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errBatchNotReplayable
+		}
+	}
+	return batch.Replay(transactionReplay{tr})
+}
+
+// NewIterator returns an iterator over the DB with the pending writes of the
+// transaction merged in, limited to the keys within slice as with
+// DB.NewIterator. The iterator is released when the transaction is closed.
+func (tr *DBTransaction) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return iterator.NewEmptyIterator(errTransactionDone)
+	}
+	// The base iterator keeps pointers to the read options, so it gets its
+	// own copy that is freed with it. The snapshot and the iterate bounds
+	// only apply to the base iterator: the pending writes are limited to
+	// slice by the iterator itself.
+	var (
+		opts = tr.db.readOptions(ro)
+		free func()
+	)
+	if slice == nil {
+		opts = opts.copy()
+		free = opts.Destroy
+	} else {
+		opts, free = opts.withBounds(slice)
+	}
+	cBase := C.rocksdb_create_iterator(tr.db.c, opts.c)
+	cIter := C.rocksdb_writebatch_wi_create_iterator_with_base(tr.c, cBase)
+	var iter *iterator.Iterator
+	if slice == nil {
+		iter = iterator.NewNativeIteratorWithCleanup(unsafe.Pointer(cIter), free)
+	} else {
+		iter = iterator.NewNativeIteratorWithBounds(unsafe.Pointer(cIter), slice, tr.db.compare, free)
+	}
+	tr.iters = append(tr.iters, iter)
+	return iter
+}
+
+// Commit commits the transaction. If error is not nil, then the transaction
+// is not committed, it can then either be retried or discarded.
+//
+// Other methods should not be called after transaction has been committed.
+func (tr *DBTransaction) Commit() error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	var cSize C.size_t
+	cData := C.rocksdb_writebatch_wi_data(tr.c, &cSize)
+	batch := (*Batch)(NewNativeWriteBatch(C.rocksdb_writebatch_create_from(cData, cSize)))
+	defer batch.Destroy()
+	if err := tr.db.Write(batch, nil); err != nil {
+		return err
+	}
+	tr.close()
+	return nil
+}
+
+// Discard discards the transaction.
+//
+// Other methods should not be called after transaction has been discarded.
+func (tr *DBTransaction) Discard() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if !tr.done {
+		tr.close()
+	}
+}
+
+func (tr *DBTransaction) close() {
+	for _, iter := range tr.iters {
+		iter.Release()
+	}
+	tr.iters = nil
+	C.rocksdb_writebatch_wi_destroy(tr.c)
+	tr.c = nil
+	tr.done = true
+}
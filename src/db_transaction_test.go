@@ -0,0 +1,92 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"./errors"
+	"./opt"
+	"./util"
+)
+
+func TestDBTransaction(t *testing.T) {
+	db := newTestDB(t, "TestDBTransaction", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	ro := &opt.ReadOptions{}
+	ensure.Nil(t, db.Put([]byte("key1"), []byte("val1"), wo))
+	ensure.Nil(t, db.Put([]byte("key2"), []byte("val2"), wo))
+
+	tr, err := db.OpenTransaction()
+	ensure.Nil(t, err)
+	ensure.Nil(t, tr.Put([]byte("key3"), []byte("val3"), nil))
+	ensure.Nil(t, tr.Delete([]byte("key1"), nil))
+
+	// read-your-writes
+	v, err := tr.Get([]byte("key3"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val3"))
+	ok, err := tr.Has([]byte("key1"), ro)
+	ensure.Nil(t, err)
+	ensure.False(t, ok)
+
+	// nothing is visible outside the transaction yet
+	_, err = db.Get([]byte("key3"), ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	iter := tr.NewIterator(nil, ro)
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	ensure.Nil(t, iter.Error())
+	ensure.DeepEqual(t, keys, []string{"key2", "key3"})
+
+	// the pending writes are bounded like the keys of the DB
+	bounded := tr.NewIterator(&util.Range{Start: []byte("key1"), Limit: []byte("key3")}, ro)
+	ensure.True(t, bounded.Last())
+	ensure.DeepEqual(t, bounded.Key(), []byte("key2"))
+	ensure.False(t, bounded.Next())
+	bounded.Release()
+	bounded = tr.NewIterator(&util.Range{Start: []byte("key3")}, ro)
+	ensure.True(t, bounded.First())
+	ensure.DeepEqual(t, bounded.Key(), []byte("key3"))
+	ensure.False(t, bounded.Prev())
+	bounded.Release()
+
+	ensure.Nil(t, tr.Commit())
+	ensure.False(t, iter.Next())
+	ensure.NotNil(t, tr.Commit())
+
+	v, err = db.Get([]byte("key3"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val3"))
+	_, err = db.Get([]byte("key1"), ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	tr, err = db.OpenTransaction()
+	ensure.Nil(t, err)
+	ensure.Nil(t, tr.Put([]byte("key4"), []byte("val4"), nil))
+	tr.Discard()
+	_, err = db.Get([]byte("key4"), ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	// batches the transaction cannot apply are rejected as a whole
+	tr, err = db.OpenTransaction()
+	ensure.Nil(t, err)
+	defer tr.Discard()
+	var b Batch
+	defer b.Destroy()
+	ensure.Nil(t, b.Load([]byte{
+		0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0,
+		byte(WriteBatchValueRecord), 1, 'x', 1, '1',
+		byte(WriteBatchMergeRecord), 1, 'x', 1, '2',
+	}))
+	ensure.True(t, errors.Is(tr.Write(&b, nil), errors.ErrNotSupported))
+	_, err = tr.Get([]byte("x"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+}
@@ -0,0 +1,101 @@
+package rocksdb
+
+//#include "api.h"
+//#include <stdlib.h>
+import "C"
+import (
+	"runtime"
+	"unsafe"
+	"./errors"
+	"./iterator"
+	"./opt"
+	. "./util"
+)
+
+// DeleteRange removes the keys within r from the database, r.Start
+// included and r.Limit excluded, with a single range tombstone instead of
+// one deletion per key. A nil Limit extends the range to the last key of
+// the database at the time of the call: keys written past it while
+// DeleteRange runs are not deleted.
+func (db *DB) DeleteRange(wo *opt.WriteOptions, r Range) error {
+	return db.deleteRange(db.writeOptions(wo), nil, r)
+}
+
+// DeleteRangeCF is like DeleteRange but removes the keys from the column
+// family.
+func (db *DB) DeleteRangeCF(opts *WriteOptions, cf *ColumnFamilyHandle, r Range) error {
+	return db.deleteRange(opts, cf, r)
+}
+
+// DeletePrefix removes the keys starting with prefix from the database,
+// see DeleteRange. The range is computed with BytesPrefix, so it assumes
+// the default bytewise comparer. If compact is true, the dropped range is
+// compacted afterwards so that the space it held is reclaimed right away
+// rather than over the course of regular compactions.
+func (db *DB) DeletePrefix(wo *opt.WriteOptions, prefix []byte, compact bool) error {
+	r := BytesPrefix(prefix)
+	if err := db.DeleteRange(wo, *r); err != nil {
+		return err
+	}
+	if compact {
+		return db.CompactRange(*r)
+	}
+	return nil
+}
+
+// deleteRange writes the deletion of the keys within r of cf, nil meaning
+// the default column family. RocksDB range tombstones need an end key, so
+// a nil Limit is resolved to the last key of cf, which gets a deletion of
+// its own. The lookup and the write are not atomic, a key written past the
+// last key in between is left in place.
+func (db *DB) deleteRange(opts *WriteOptions, cf *ColumnFamilyHandle, r Range) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	wb := NewWriteBatch()
+	defer wb.Destroy()
+
+	limit := r.Limit
+	if limit == nil {
+		// The lower bound leaves the ordering of the keys to RocksDB: the
+		// last key found is the last one at or after r.Start.
+		bounded, free := db.readOptions(nil).withBounds(&Range{Start: r.Start})
+		var cIter *C.rocksdb_iterator_t
+		if cf == nil {
+			cIter = C.rocksdb_create_iterator(db.c, bounded.c)
+		} else {
+			cIter = C.rocksdb_create_iterator_cf(db.c, bounded.c, cf.c)
+		}
+		iter := iterator.NewNativeIteratorWithCleanup(unsafe.Pointer(cIter), free)
+		found := iter.Last()
+		last := iter.Key()
+		err := iter.Err()
+		iter.Release()
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		limit = last
+		if cf == nil {
+			wb.Delete(last)
+		} else {
+			wb.DeleteCF(cf, last)
+		}
+	}
+	if cf == nil {
+		wb.DeleteRange(r.Start, limit)
+	} else {
+		wb.DeleteRangeCF(cf, r.Start, limit)
+	}
+
+	var cErr *C.char
+	C.rocksdb_write(db.c, opts.c, wb.c, &cErr)
+	runtime.KeepAlive(wb)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return errors.FromStatus(C.GoString(cErr))
+	}
+	return nil
+}
@@ -11,43 +11,54 @@ rocksdb.OpenDb opens and creates databases.
 
 The DB struct returned by OpenDb provides DB.Get, DB.Put, DB.Merge and DB.Delete to modify
 and query the database.
-	ro := rocksdb.NewDefaultReadOptions()
-	wo := rocksdb.NewDefaultWriteOptions()
-	// if ro and wo are not used again, be sure to Close them.
-	err = db.Put(wo, []byte("foo"), []byte("bar"))
+The read and write options are goleveldb style opt.ReadOptions and
+opt.WriteOptions values, nil meaning the defaults; the DB translates them
+into native options itself.
+	err = db.Put([]byte("foo"), []byte("bar"), nil)
 	...
-	value, err := db.Get(ro, []byte("foo"))
-	defer value.Free()
+	value, err := db.Get([]byte("foo"), nil)
 	...
-	err = db.Delete(wo, []byte("foo"))
+	err = db.Delete([]byte("foo"), &opt.WriteOptions{Sync: true})
 
 For bulk reads, use an Iterator. If you want to avoid disturbing your live
-traffic while doing the bulk read, be sure to call SetFillCache(false) on the
+traffic while doing the bulk read, be sure to set DontFillCache on the
 ReadOptions you use when creating the Iterator.
-	ro := rocksdb.NewDefaultReadOptions()
-	ro.SetFillCache(false)
-	it := db.NewIterator(ro)
+	it := db.NewIterator(nil, &opt.ReadOptions{DontFillCache: true})
 	defer it.Close()
 	it.Seek([]byte("foo"))
 	for it = it; it.Valid(); it.Next() {
 		key := it.Key()
 		value := it.Value()
-		fmt.Printf("Key: %v Value: %v\n", key.Data(), value.Data())
-		key.Free()
-		value.Free()
+		fmt.Printf("Key: %v Value: %v\n", key, value)
 	}
 	if err := it.Err(); err != nil {
 		...
 	}
 
-Batched, atomic writes can be performed with a WriteBatch and
+Key and Value return copies. KeyUnsafe and ValueUnsafe avoid the copy;
+the slices they return are only valid until the iterator is moved.
+
+Batched, atomic writes can be performed with a Batch and
 DB.Write.
-	wb := rocksdb.NewWriteBatch()
-	// defer wb.Close or use wb.Clear and reuse.
+	wb := rocksdb.NewBatch()
+	// use wb.Reset to reuse it.
 	wb.Delete([]byte("foo"))
 	wb.Put([]byte("foo"), []byte("bar"))
 	wb.Put([]byte("bar"), []byte("foo"))
-	err := db.Write(wo, wb)
+	err := db.Write(wb, nil)
+
+To drop a whole span of keys, use DB.DeleteRange or DB.DeletePrefix, which
+write a single range tombstone instead of one deletion per key.
+	err = db.DeletePrefix(nil, []byte("tenant1/"), true)
+
+DB.Bucket gives the goleveldb style API access to a column family, which
+is created on first use. A Batch can update several buckets atomically.
+	users, err := db.Bucket("users")
+	...
+	wb := rocksdb.NewBatch()
+	users.Batch(wb).Put([]byte("alice"), []byte("..."))
+	wb.Put([]byte("last-user"), []byte("alice"))
+	err = db.Write(wb, nil)
 
 If your working dataset does not fit in memory, you'll want to add a bloom
 filter to your database. NewBloomFilter and
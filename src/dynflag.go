@@ -1,4 +1,5 @@
 package rocksdb
 
 // #cgo LDFLAGS: -lrocksdb -lstdc++ -lm -lz -lbz2 -lsnappy
+// #cgo CXXFLAGS: -std=c++17
 import "C"
@@ -1,11 +1,11 @@
 package rocksdb
 
 import (
-	"errors"
-	. "./constants"
+	"./errors"
 )
 
 // Common errors (in alphabetical order)
 var (
-	ErrNotFound         = errors.New(PkgName + ": not found")
+	ErrNotFound         = errors.ErrNotFound
+	ErrSnapshotReleased = errors.ErrSnapshotReleased
 )
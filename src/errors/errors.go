@@ -13,7 +13,7 @@ import (
 var (
 	ErrClosed           = errors.New(PkgName + ": closed")
 	ErrHasReleaser      = util.ErrHasReleaser
-	ErrIterReleased     = errors.New(PkgName + ": iterator released")
+	ErrIterReleased     = util.ErrIterReleased
 	ErrNotFound         = New(PkgName + ": not found")
 	ErrReadOnly         = errors.New(PkgName + ": read-only mode")
 	ErrReleased         = util.ErrReleased
@@ -25,6 +25,22 @@ func New(text string) error {
 	return errors.New(text)
 }
 
+// Is reports whether any error in err's chain matches target.
+func Is(err, target error) bool {
+	return errors.Is(err, target)
+}
+
+// As finds the first error in err's chain that matches target, and if so,
+// sets target to that error value and returns true.
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
+
+// Unwrap returns the result of calling the Unwrap method on err, if any.
+func Unwrap(err error) error {
+	return errors.Unwrap(err)
+}
+
 // ErrCorrupted is the type that wraps errors that indicate corruption in
 // the database.
 type ErrCorrupted struct {
@@ -39,6 +55,9 @@ func (e *ErrCorrupted) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the underlying error.
+func (e *ErrCorrupted) Unwrap() error { return e.Err }
+
 // NewErrCorrupted creates new ErrCorrupted error.
 func NewErrCorrupted(fd storage.FileDesc, err error) error {
 	return &ErrCorrupted{fd, err}
@@ -47,13 +66,11 @@ func NewErrCorrupted(fd storage.FileDesc, err error) error {
 // IsCorrupted returns a boolean indicating whether the error is indicating
 // a corruption.
 func IsCorrupted(err error) bool {
-	switch err.(type) {
-	case *ErrCorrupted:
-		return true
-	case *storage.ErrCorrupted:
-		return true
-	}
-	return false
+	var (
+		e1 *ErrCorrupted
+		e2 *storage.ErrCorrupted
+	)
+	return errors.As(err, &e1) || errors.As(err, &e2)
 }
 
 // ErrMissingFiles is the type that indicating a corruption due to missing
@@ -0,0 +1,152 @@
+package errors
+
+import (
+	"errors"
+	"strings"
+
+	. "../constants"
+)
+
+// Code is the code of a RocksDB status.
+type Code int
+
+// Status codes. CodeUnknown is used for messages without a known status
+// prefix.
+const (
+	CodeUnknown Code = iota
+	CodeNotFound
+	CodeCorruption
+	CodeNotSupported
+	CodeInvalidArgument
+	CodeIOError
+	CodeIncomplete
+	CodeShutdownInProgress
+	CodeTimedOut
+	CodeAborted
+	CodeBusy
+	CodeTryAgain
+	CodeNoSpace
+)
+
+func (c Code) String() string {
+	switch c {
+	case CodeNotFound:
+		return "NotFound"
+	case CodeCorruption:
+		return "Corruption"
+	case CodeNotSupported:
+		return "NotSupported"
+	case CodeInvalidArgument:
+		return "InvalidArgument"
+	case CodeIOError:
+		return "IOError"
+	case CodeIncomplete:
+		return "Incomplete"
+	case CodeShutdownInProgress:
+		return "ShutdownInProgress"
+	case CodeTimedOut:
+		return "TimedOut"
+	case CodeAborted:
+		return "Aborted"
+	case CodeBusy:
+		return "Busy"
+	case CodeTryAgain:
+		return "TryAgain"
+	case CodeNoSpace:
+		return "NoSpace"
+	}
+	return "Unknown"
+}
+
+// Status errors, matched with errors.Is against the errors returned by
+// FromStatus. A NoSpace status also matches ErrIOError, and a NotFound
+// status matches ErrNotFound.
+var (
+	ErrAborted            = errors.New(PkgName + ": operation aborted")
+	ErrBusy               = errors.New(PkgName + ": resource busy")
+	ErrCorruption         = errors.New(PkgName + ": corruption")
+	ErrIncomplete         = errors.New(PkgName + ": result incomplete")
+	ErrInvalidArgument    = errors.New(PkgName + ": invalid argument")
+	ErrIOError            = errors.New(PkgName + ": IO error")
+	ErrNoSpace            = errors.New(PkgName + ": no space left on device")
+	ErrNotSupported       = errors.New(PkgName + ": not supported")
+	ErrShutdownInProgress = errors.New(PkgName + ": shutdown in progress")
+	ErrTimedOut           = errors.New(PkgName + ": operation timed out")
+	ErrTryAgain           = errors.New(PkgName + ": try again")
+)
+
+var statusErrs = map[Code]error{
+	CodeNotFound:           ErrNotFound,
+	CodeCorruption:         ErrCorruption,
+	CodeNotSupported:       ErrNotSupported,
+	CodeInvalidArgument:    ErrInvalidArgument,
+	CodeIOError:            ErrIOError,
+	CodeIncomplete:         ErrIncomplete,
+	CodeShutdownInProgress: ErrShutdownInProgress,
+	CodeTimedOut:           ErrTimedOut,
+	CodeAborted:            ErrAborted,
+	CodeBusy:               ErrBusy,
+	CodeTryAgain:           ErrTryAgain,
+	CodeNoSpace:            ErrNoSpace,
+}
+
+// statusPrefixes are the prefixes Status::ToString starts the message
+// with, per status code.
+var statusPrefixes = []struct {
+	prefix string
+	code   Code
+}{
+	{"NotFound: ", CodeNotFound},
+	{"Corruption: ", CodeCorruption},
+	{"Not implemented: ", CodeNotSupported},
+	{"Invalid argument: ", CodeInvalidArgument},
+	{"IO error: ", CodeIOError},
+	{"Result incomplete: ", CodeIncomplete},
+	{"Shutdown in progress: ", CodeShutdownInProgress},
+	{"Operation timed out: ", CodeTimedOut},
+	{"Operation aborted: ", CodeAborted},
+	{"Resource busy: ", CodeBusy},
+	{"Operation failed. Try again.: ", CodeTryAgain},
+}
+
+// noSpaceMsg is the IO error subcode message of a NoSpace status.
+const noSpaceMsg = "No space left on device"
+
+// ErrStatus is the type of errors reported by RocksDB as a status.
+type ErrStatus struct {
+	Code Code
+	// Msg is the message following the status prefix.
+	Msg  string
+	text string
+}
+
+func (e *ErrStatus) Error() string { return e.text }
+
+// Is reports whether the status matches the given status error.
+func (e *ErrStatus) Is(target error) bool {
+	if e.Code == CodeNoSpace && target == ErrIOError {
+		return true
+	}
+	return target != nil && target == statusErrs[e.Code]
+}
+
+// FromStatus translates a RocksDB status message into a typed error.
+// The error is an *ErrStatus, except for a Corruption status, which is
+// returned as an *ErrCorrupted wrapping the *ErrStatus.
+func FromStatus(msg string) error {
+	e := &ErrStatus{Msg: msg, text: msg}
+	for _, p := range statusPrefixes {
+		if strings.HasPrefix(msg, p.prefix) {
+			e.Code = p.code
+			e.Msg = msg[len(p.prefix):]
+			break
+		}
+	}
+	if e.Code == CodeIOError && strings.HasPrefix(e.Msg, noSpaceMsg) {
+		e.Code = CodeNoSpace
+	}
+	if e.Code == CodeCorruption {
+		return &ErrCorrupted{Err: e}
+	}
+	return e
+}
@@ -0,0 +1,61 @@
+package errors
+
+import (
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+func TestFromStatus(t *testing.T) {
+	cases := []struct {
+		msg    string
+		code   Code
+		target error
+	}{
+		{"NotFound: ", CodeNotFound, ErrNotFound},
+		{"Not implemented: ", CodeNotSupported, ErrNotSupported},
+		{"Invalid argument: Column family not found", CodeInvalidArgument, ErrInvalidArgument},
+		{"IO error: While open a file for appending: /tmp/db/000001.log", CodeIOError, ErrIOError},
+		{"IO error: No space left on device: While appending", CodeNoSpace, ErrNoSpace},
+		{"Result incomplete: ", CodeIncomplete, ErrIncomplete},
+		{"Shutdown in progress: ", CodeShutdownInProgress, ErrShutdownInProgress},
+		{"Operation timed out: Timeout waiting to lock key", CodeTimedOut, ErrTimedOut},
+		{"Operation aborted: ", CodeAborted, ErrAborted},
+		{"Resource busy: ", CodeBusy, ErrBusy},
+		{"Operation failed. Try again.: ", CodeTryAgain, ErrTryAgain},
+	}
+	for _, c := range cases {
+		err := FromStatus(c.msg)
+		ensure.DeepEqual(t, err.Error(), c.msg)
+		ensure.True(t, Is(err, c.target), c.msg)
+		ensure.False(t, IsCorrupted(err), c.msg)
+
+		var status *ErrStatus
+		ensure.True(t, As(err, &status), c.msg)
+		ensure.DeepEqual(t, status.Code, c.code)
+	}
+
+	// NoSpace is a kind of IO error
+	ensure.True(t, Is(FromStatus("IO error: No space left on device"), ErrIOError))
+	ensure.False(t, Is(FromStatus("IO error: "), ErrNoSpace))
+
+	err := FromStatus("unexpected")
+	ensure.DeepEqual(t, err.Error(), "unexpected")
+	ensure.DeepEqual(t, err.(*ErrStatus).Code, CodeUnknown)
+}
+
+func TestFromStatusCorruption(t *testing.T) {
+	msg := "Corruption: block checksum mismatch"
+	err := FromStatus(msg)
+	ensure.DeepEqual(t, err.Error(), msg)
+	ensure.True(t, IsCorrupted(err))
+	ensure.True(t, Is(err, ErrCorruption))
+
+	_, ok := err.(*ErrCorrupted)
+	ensure.True(t, ok)
+
+	var status *ErrStatus
+	ensure.True(t, As(err, &status))
+	ensure.DeepEqual(t, status.Code, CodeCorruption)
+	ensure.DeepEqual(t, status.Msg, "block checksum mismatch")
+}
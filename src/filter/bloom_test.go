@@ -0,0 +1,58 @@
+package filter
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+)
+
+type sliceBuffer []byte
+
+func (b *sliceBuffer) Alloc(n int) []byte {
+	off := len(*b)
+	*b = append(*b, make([]byte, n)...)
+	return (*b)[off:]
+}
+
+func (b *sliceBuffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+func (b *sliceBuffer) WriteByte(c byte) error {
+	*b = append(*b, c)
+	return nil
+}
+
+func TestBloomFilter(t *testing.T) {
+	f := NewBloomFilter(10)
+	ensure.DeepEqual(t, f.Name(), BloomFilterName)
+
+	key := func(i int) []byte {
+		b := make([]byte, 4)
+		binary.LittleEndian.PutUint32(b, uint32(i))
+		return b
+	}
+
+	g := f.NewGenerator()
+	for i := 0; i < 1000; i++ {
+		g.Add(key(i))
+	}
+	var b sliceBuffer
+	g.Generate(&b)
+
+	for i := 0; i < 1000; i++ {
+		ensure.True(t, f.Contains(b, key(i)), i)
+	}
+	var falsePositives int
+	for i := 1000; i < 11000; i++ {
+		if f.Contains(b, key(i)) {
+			falsePositives++
+		}
+	}
+	// ~1% false positive rate for 10 bits per key
+	ensure.True(t, falsePositives < 200, falsePositives)
+
+	ensure.False(t, f.Contains(nil, key(0)))
+}
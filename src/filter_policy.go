@@ -1,7 +1,15 @@
 package rocksdb
 
 //#include "api.h"
+//#include <stdlib.h>
 import "C"
+import (
+	"encoding/binary"
+	"unsafe"
+
+	. "./constants"
+	"./filter"
+)
 
 // FilterPolicy is a factory type that allows the RocksDB database to create a
 // filter, such as a bloom filter, which will used to reduce reads.
@@ -49,7 +57,95 @@ func NewBloomFilter(bitsPerKey int) FilterPolicy {
 	return NewNativeFilterPolicy(C.rocksdb_filterpolicy_create_bloom(C.int(bitsPerKey)))
 }
 
-// Hold references to filter policies.
+// goFilterPolicyName is the name of the filter policies created by
+// NewFilterPolicy for Go filters. The name of the Go filter is stored in
+// each filter block instead, so alternative filters can be matched.
+const goFilterPolicyName = PkgName + ".GoFilter"
+
+// NewFilterPolicy returns a FilterPolicy for the given filter.Filter.
+// The bloom filter from filter.NewBloomFilter is mapped to RocksDB's
+// native bloom filter. Other filters are called back into: each filter
+// block records the name of the filter that generated it, and blocks
+// generated by one of the alternative filters are read with that filter.
+func NewFilterPolicy(f filter.Filter, alt ...filter.Filter) FilterPolicy {
+	if bf, ok := f.(bloomBitsPerKey); ok && f.Name() == filter.BloomFilterName {
+		return NewBloomFilter(bf.BitsPerKey())
+	}
+	filters := make(map[string]filter.Filter, len(alt)+1)
+	for _, af := range alt {
+		filters[af.Name()] = af
+	}
+	filters[f.Name()] = f
+	return goFilterPolicy{f: f, filters: filters}
+}
+
+// bloomBitsPerKey is implemented by the bloom filter of the filter package.
+type bloomBitsPerKey interface {
+	BitsPerKey() int
+}
+
+// goFilterPolicy adapts a filter.Filter to a FilterPolicy. The filter
+// blocks are the generated filter followed by the name of the filter and
+// its length as a 16 bit little endian integer.
+type goFilterPolicy struct {
+	f       filter.Filter
+	filters map[string]filter.Filter
+}
+
+func (fp goFilterPolicy) CreateFilter(keys [][]byte) []byte {
+	g := fp.f.NewGenerator()
+	for _, key := range keys {
+		g.Add(key)
+	}
+	var b filterBuffer
+	g.Generate(&b)
+	name := fp.f.Name()
+	b.Write([]byte(name))
+	binary.LittleEndian.PutUint16(b.Alloc(2), uint16(len(name)))
+	return b
+}
+
+func (fp goFilterPolicy) KeyMayMatch(key []byte, filter []byte) bool {
+	if len(filter) < 2 {
+		return true
+	}
+	n := int(binary.LittleEndian.Uint16(filter[len(filter)-2:]))
+	if len(filter) < n+2 {
+		return true
+	}
+	data, name := filter[:len(filter)-n-2], filter[len(filter)-n-2:len(filter)-2]
+	f, ok := fp.filters[string(name)]
+	if !ok {
+		// The block was generated by an unknown filter.
+		return true
+	}
+	return f.Contains(data, key)
+}
+
+func (fp goFilterPolicy) Name() string { return goFilterPolicyName }
+
+// filterBuffer is the filter.Buffer the Go filters are generated into.
+type filterBuffer []byte
+
+func (b *filterBuffer) Alloc(n int) []byte {
+	off := len(*b)
+	*b = append(*b, make([]byte, n)...)
+	return (*b)[off:]
+}
+
+func (b *filterBuffer) Write(p []byte) (int, error) {
+	*b = append(*b, p...)
+	return len(p), nil
+}
+
+func (b *filterBuffer) WriteByte(c byte) error {
+	*b = append(*b, c)
+	return nil
+}
+
+// Hold references to filter policies. The entries are deleted when RocksDB
+// destroys the filter policy, which happens once the table options, the
+// Options and the DB using it are all destroyed or closed.
 var filterPolicies = NewCOWList()
 
 type filterPolicyWrapper struct {
@@ -85,4 +181,10 @@ func itf_filterpolicy_key_may_match(idx int, cKey *C.char, cKeyLen C.size_t, cFi
 //export itf_filterpolicy_name
 func itf_filterpolicy_name(idx int) *C.char {
 	return filterPolicies.Get(idx).(filterPolicyWrapper).name
-}
\ No newline at end of file
+}
+
+//export itf_filterpolicy_destroy
+func itf_filterpolicy_destroy(idx int) {
+	C.free(unsafe.Pointer(filterPolicies.Get(idx).(filterPolicyWrapper).name))
+	filterPolicies.Delete(idx)
+}
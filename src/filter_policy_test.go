@@ -1,9 +1,16 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
+	"io/ioutil"
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	. "./constants"
+	"./filter"
+	"./opt"
 )
 
 // fatalAsError is used as a wrapper to make it possible to use ensure
@@ -45,9 +52,9 @@ func TestFilterPolicy(t *testing.T) {
 	defer db.Close()
 
 	// insert keys
-	wo := NewDefaultWriteOptions()
+	wo := &opt.WriteOptions{}
 	for _, k := range givenKeys {
-		ensure.Nil(t, db.Put(wo, k, []byte("val")))
+		ensure.Nil(t, db.Put(k, []byte("val"), wo))
 	}
 
 	// flush to trigger the filter creation
@@ -55,13 +62,62 @@ func TestFilterPolicy(t *testing.T) {
 	ensure.True(t, createFilterCalled)
 
 	// test key may match call
-	ro := NewDefaultReadOptions()
-	v1, err := db.Get(ro, givenKeys[0])
-	defer v1.Free()
+	ro := &opt.ReadOptions{}
+	v1, err := db.Get(givenKeys[0], ro)
 	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v1, []byte("val"))
 	ensure.True(t, keyMayMatchCalled)
 }
 
+func TestFilterPolicyAltFilters(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestFilterPolicyAltFilters")
+	ensure.Nil(t, err)
+
+	var (
+		oldFilter = &namedFilter{Filter: filter.NewBloomFilter(10), name: "test.old"}
+		newFilter = &namedFilter{Filter: filter.NewBloomFilter(10), name: "test.new"}
+		wo        = &opt.WriteOptions{}
+		ro        = &opt.ReadOptions{}
+	)
+
+	db, err := OpenFile(dir, &opt.Options{Filter: oldFilter})
+	ensure.Nil(t, err)
+	ensure.Nil(t, db.Put([]byte("key1"), []byte("val"), wo))
+	ensure.Nil(t, db.Flush(NewDefaultFlushOptions()))
+	_, err = db.Get([]byte("key2"), ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	ensure.True(t, oldFilter.contains > 0)
+	idx := len(filterPolicies.v.Load().([]interface{})) - 1
+	ensure.NotNil(t, filterPolicies.Get(idx))
+	ensure.Nil(t, db.Close())
+	// the filter policy of the DB is freed with it
+	ensure.Nil(t, filterPolicies.Get(idx))
+
+	// the table written with the old filter is read with the alternative filter
+	oldFilter.contains = 0
+	db, err = OpenFile(dir, &opt.Options{Filter: newFilter, AltFilters: []filter.Filter{oldFilter}})
+	ensure.Nil(t, err)
+	defer db.Close()
+	v, err := db.Get([]byte("key1"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val"))
+	ensure.True(t, oldFilter.contains > 0)
+	ensure.DeepEqual(t, newFilter.contains, 0)
+}
+
+// namedFilter renames a filter and counts the calls to Contains.
+type namedFilter struct {
+	filter.Filter
+	name     string
+	contains int
+}
+
+func (f *namedFilter) Name() string { return f.name }
+func (f *namedFilter) Contains(filter, key []byte) bool {
+	f.contains++
+	return f.Filter.Contains(filter, key)
+}
+
 type mockFilterPolicy struct {
 	createFilter func(keys [][]byte) []byte
 	keyMayMatch  func(key, filter []byte) bool
@@ -0,0 +1,75 @@
+package iterator
+
+import (
+	"bytes"
+	. "../util"
+)
+
+type dir int
+
+const (
+	dirReleased dir = iota - 1
+	dirSOI
+	dirEOI
+	dirBackward
+	dirForward
+)
+
+// ValidForPrefix returns false only when an Iterator has iterated past the
+// first or the last key in the database or the specified prefix.
+func (iter *Iterator) ValidForPrefix(prefix []byte) bool {
+	if !iter.Valid() {
+		return false
+	}
+
+	key := iter.KeyUnsafe()
+	result := bytes.HasPrefix(key/*.Data()*/, prefix)
+	//key.Free()
+	return result
+}
+
+func (iter *Iterator) released() bool {
+	if iter.dir == dirReleased {
+		if iter.err == nil {
+			iter.err = ErrIterReleased
+		}
+		return true
+	}
+	return false
+}
+
+// SeekToFirst moves the iterator to the first key in the database.
+func (iter *Iterator) SeekToFirst() {
+	iter.First()
+}
+
+// SeekToLast moves the iterator to the last key in the database.
+func (iter *Iterator) SeekToLast() {
+	iter.Last()
+}
+
+// Error is the goleveldb name of Err.
+func (iter *Iterator) Error() error {
+	return iter.Err()
+}
+
+// Close closes the iterator. It is the same as Release.
+func (iter *Iterator) Close() {
+	iter.Release()
+}
+
+// SetReleaser associates the given releaser to the iterator. The releaser
+// will be called once the iterator is released. Calling SetReleaser with
+// nil will clear the releaser.
+//
+// This will panic if a releaser already present or the iterator is already
+// released.
+func (iter *Iterator) SetReleaser(releaser Releaser) {
+	if iter.dir == dirReleased {
+		panic(ErrReleased)
+	}
+	if iter.releaser != nil && releaser != nil {
+		panic(ErrHasReleaser)
+	}
+	iter.releaser = releaser
+}
@@ -6,8 +6,8 @@ package iterator
 //#cgo LDFLAGS: -lrocksdb -lstdc++ -lm -lz -lbz2 -lsnappy
 import "C"
 import (
-	"bytes"
-	"errors"
+	"../errors"
+	"reflect"
 	"unsafe"
 	. "../util"
 )
@@ -15,124 +15,320 @@ import (
 // Iterator provides a way to seek to specific keys and iterate through
 // the keyspace from that point, as well as access the values of those keys.
 //
-// For example:
+// A new Iterator is not positioned; the first call to Next moves it to the
+// first key, while Prev returns false until the iterator has been moved:
 //
-//      it := db.NewIterator(readOpts)
-//      defer it.Close()
-//
-//      it.Seek([]byte("foo"))
-//		for ; it.Valid(); it.Next() {
-//          fmt.Printf("Key: %v Value: %v\n", it.Key().Data(), it.Value().Data())
-// 		}
+//      iter := db.NewIterator(nil, readOpts)
+//      for iter.Next() {
+//          fmt.Printf("Key: %v Value: %v\n", iter.Key(), iter.Value())
+//      }
+//      iter.Release()
 //
-//      if err := it.Err(); err != nil {
+//      if err := iter.Error(); err != nil {
 //          return err
 //      }
 //
+// An Iterator is not safe for concurrent use, but it is safe to use
+// multiple iterators concurrently.
 type Iterator struct {
-	c *C.rocksdb_iterator_t
+	c   *C.rocksdb_iterator_t
+	dir dir
+	err error
+
+	releaser Releaser
+
+	// cleanup releases resources the native iterator refers to, such as
+	// read options and iterate bounds. It runs after the iterator is destroyed.
+	cleanup func()
+
+	// slice, if set, limits the iterator to the keys within it as ordered
+	// by cmp, for native iterators that do not apply iterate bounds to all
+	// of their keys.
+	slice *Range
+	cmp   func(a, b []byte) int
 }
 
 // NewNativeIterator creates a Iterator object.
 func NewNativeIterator(c unsafe.Pointer) *Iterator {
-	return &Iterator{(*C.rocksdb_iterator_t)(c)}
+	return &Iterator{c: (*C.rocksdb_iterator_t)(c)}
 }
 
-// Valid returns false only when an Iterator has iterated past either the
-// first or the last key in the database.
-func (iter *Iterator) Valid() bool {
-	return C.rocksdb_iter_valid(iter.c) != 0
+// NewNativeIteratorWithCleanup creates a Iterator object which calls cleanup
+// once the underlying iterator has been closed.
+func NewNativeIteratorWithCleanup(c unsafe.Pointer, cleanup func()) *Iterator {
+	return &Iterator{c: (*C.rocksdb_iterator_t)(c), cleanup: cleanup}
 }
 
-// ValidForPrefix returns false only when an Iterator has iterated past the
-// first or the last key in the database or the specified prefix.
-func (iter *Iterator) ValidForPrefix(prefix []byte) bool {
-	if C.rocksdb_iter_valid(iter.c) == 0 {
-		return false
+// NewNativeIteratorWithBounds is like NewNativeIteratorWithCleanup, but the
+// iterator skips the keys outside slice, ordered by cmp. A nil Start or
+// Limit is unbounded, as with iterate bounds. The slice is copied.
+func NewNativeIteratorWithBounds(c unsafe.Pointer, slice *Range, cmp func(a, b []byte) int, cleanup func()) *Iterator {
+	r := &Range{Start: append([]byte(nil), slice.Start...)}
+	if slice.Limit != nil {
+		r.Limit = append([]byte{}, slice.Limit...)
 	}
+	return &Iterator{c: (*C.rocksdb_iterator_t)(c), cleanup: cleanup, slice: r, cmp: cmp}
+}
+
+// NewEmptyIterator creates an empty, already released iterator whose
+// Error method returns err.
+func NewEmptyIterator(err error) *Iterator {
+	return &Iterator{dir: dirReleased, err: err}
+}
 
-	key := iter.Key()
-	result := bytes.HasPrefix(key/*.Data()*/, prefix)
-	//key.Free()
-	return result
+// Valid returns whether the iterator is positioned at a key/value pair.
+// It returns false on a new, exhausted or released iterator.
+func (iter *Iterator) Valid() bool {
+	if iter.dir == dirReleased {
+		return false
+	}
+	if iter.slice != nil && (iter.dir == dirSOI || iter.dir == dirEOI) {
+		// The native iterator may be positioned outside the slice.
+		return false
+	}
+	return C.rocksdb_iter_valid(iter.c) != 0
 }
 
-// Key returns the key the iterator currently holds.
+// Key returns the key the iterator currently holds, or nil if the
+// iterator is not positioned at a key. The returned slice is a copy owned
+// by the caller.
 func (iter *Iterator) Key() /**Slice*/[]byte {
+	if !iter.Valid() {
+		return nil
+	}
 	var cLen C.size_t
 	cKey := C.rocksdb_iter_key(iter.c, &cLen)
 	if cKey == nil {
 		return nil
 	}
-	return /*&Slice{cKey, cLen, true}*/StringToSlice(C.GoStringN(cKey, (C.int)(cLen))).Data()
+	return /*&Slice{cKey, cLen, true}*/C.GoBytes(unsafe.Pointer(cKey), C.int(cLen))
 }
 
-// Value returns the value in the database the iterator currently holds.
+// Value returns the value in the database the iterator currently holds,
+// or nil if the iterator is not positioned at a key. The returned slice
+// is a copy owned by the caller.
 func (iter *Iterator) Value() /**Slice*/[]byte {
+	if !iter.Valid() {
+		return nil
+	}
 	var cLen C.size_t
 	cVal := C.rocksdb_iter_value(iter.c, &cLen)
 	if cVal == nil {
 		return nil
-  }
-	return /*&Slice{cVal, cLen, true}*/StringToSlice(C.GoStringN(cVal, (C.int)(cLen))).Data()
+	}
+	return /*&Slice{cVal, cLen, true}*/C.GoBytes(unsafe.Pointer(cVal), C.int(cLen))
 }
 
-// Next moves the iterator to the next sequential key in the database.
-func (iter *Iterator) Next() bool {
-  C.rocksdb_iter_next(iter.c)
-  return false
+// KeyUnsafe is like Key but returns a view of the key held by the native
+// iterator, without copying it. The view must not be modified, and is only
+// valid until the iterator is moved or released.
+func (iter *Iterator) KeyUnsafe() []byte {
+	if !iter.Valid() {
+		return nil
+	}
+	var cLen C.size_t
+	cKey := C.rocksdb_iter_key(iter.c, &cLen)
+	return charToByte(cKey, cLen)
 }
 
-// Prev moves the iterator to the previous sequential key in the database.
-func (iter *Iterator) Prev() {
-	C.rocksdb_iter_prev(iter.c)
+// ValueUnsafe is like Value but returns a view of the value held by the
+// native iterator, without copying it. The view must not be modified, and
+// is only valid until the iterator is moved or released.
+func (iter *Iterator) ValueUnsafe() []byte {
+	if !iter.Valid() {
+		return nil
+	}
+	var cLen C.size_t
+	cVal := C.rocksdb_iter_value(iter.c, &cLen)
+	return charToByte(cVal, cLen)
+}
+
+// byteToChar returns *C.char from byte slice, nil for an empty slice.
+func byteToChar(b []byte) *C.char {
+	var c *C.char
+	if len(b) > 0 {
+		c = (*C.char)(unsafe.Pointer(&b[0]))
+	}
+	return c
+}
+
+// charToByte converts a *C.char to a byte slice, without copying it.
+func charToByte(data *C.char, len C.size_t) []byte {
+	if data == nil {
+		return nil
+	}
+	var value []byte
+	sH := (*reflect.SliceHeader)(unsafe.Pointer(&value))
+	sH.Cap, sH.Len, sH.Data = int(len), int(len), uintptr(unsafe.Pointer(data))
+	return value
+}
+
+// settle records the direction the iterator moved in and reports whether
+// it landed on a key. Running off either end leaves it at that end, so
+// that moving back in the opposite direction restarts from there.
+func (iter *Iterator) settle(d dir) bool {
+	if C.rocksdb_iter_valid(iter.c) != 0 && iter.inSlice() {
+		iter.dir = d
+		return true
+	}
+	if d == dirForward {
+		iter.dir = dirEOI
+	} else {
+		iter.dir = dirSOI
+	}
+	return false
 }
 
-// SeekToFirst moves the iterator to the first key in the database.
-func (iter *Iterator) SeekToFirst() {
+// inSlice reports whether the key of the native iterator is within the
+// slice of the iterator, if any.
+func (iter *Iterator) inSlice() bool {
+	if iter.slice == nil {
+		return true
+	}
+	var cLen C.size_t
+	key := charToByte(C.rocksdb_iter_key(iter.c, &cLen), cLen)
+	if len(iter.slice.Start) > 0 && iter.cmp(key, iter.slice.Start) < 0 {
+		return false
+	}
+	return iter.slice.Limit == nil || iter.cmp(key, iter.slice.Limit) < 0
+}
+
+// seekToLast moves the native iterator to the last key before the Limit of
+// the slice, if any.
+func (iter *Iterator) seekToLast() {
+	if iter.slice == nil || iter.slice.Limit == nil {
+		C.rocksdb_iter_seek_to_last(iter.c)
+		return
+	}
+	limit := iter.slice.Limit
+	C.rocksdb_iter_seek_for_prev(iter.c, byteToChar(limit), C.size_t(len(limit)))
+	if C.rocksdb_iter_valid(iter.c) != 0 {
+		var cLen C.size_t
+		if iter.cmp(charToByte(C.rocksdb_iter_key(iter.c, &cLen), cLen), limit) >= 0 {
+			C.rocksdb_iter_prev(iter.c)
+		}
+	}
+}
+
+// First moves the iterator to the first key/value pair. If the iterator
+// only contains one key/value pair then First and Last would moves
+// to the same key/value pair.
+// It returns whether such pair exist.
+func (iter *Iterator) First() bool {
+	if iter.released() {
+		return false
+	}
+	if iter.slice != nil && len(iter.slice.Start) > 0 {
+		return iter.Seek(iter.slice.Start)
+	}
 	C.rocksdb_iter_seek_to_first(iter.c)
+	return iter.settle(dirForward)
+}
+
+// Last moves the iterator to the last key/value pair. If the iterator
+// only contains one key/value pair then First and Last would moves
+// to the same key/value pair.
+// It returns whether such pair exist.
+func (iter *Iterator) Last() bool {
+	if iter.released() {
+		return false
+	}
+	iter.seekToLast()
+	return iter.settle(dirBackward)
+}
+
+// Next moves the iterator to the next key/value pair. A new iterator is
+// moved to the first key/value pair.
+// It returns false if the iterator is exhausted.
+func (iter *Iterator) Next() bool {
+	switch {
+	case iter.released():
+		return false
+	case iter.dir == dirSOI:
+		return iter.First()
+	case iter.dir == dirEOI:
+		return false
+	}
+	C.rocksdb_iter_next(iter.c)
+	return iter.settle(dirForward)
 }
 
-// SeekToLast moves the iterator to the last key in the database.
-func (iter *Iterator) SeekToLast() {
-	C.rocksdb_iter_seek_to_last(iter.c)
+// Prev moves the iterator to the previous key/value pair. An iterator
+// exhausted by Next is moved to the last key/value pair.
+// It returns false if the iterator is exhausted.
+func (iter *Iterator) Prev() bool {
+	switch {
+	case iter.released():
+		return false
+	case iter.dir == dirSOI:
+		return false
+	case iter.dir == dirEOI:
+		return iter.Last()
+	}
+	C.rocksdb_iter_prev(iter.c)
+	return iter.settle(dirBackward)
 }
 
-// Seek moves the iterator to the position greater than or equal to the key.
-func (iter *Iterator) Seek(key []byte) {
-	cKey := /*byteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key.
+// It returns whether such pair exist.
+func (iter *Iterator) Seek(key []byte) bool {
+	if iter.released() {
+		return false
+	}
+	if iter.slice != nil && len(iter.slice.Start) > 0 && iter.cmp(key, iter.slice.Start) < 0 {
+		key = iter.slice.Start
+	}
+	cKey := byteToChar(key)
 	C.rocksdb_iter_seek(iter.c, (*C.char)(cKey), C.size_t(len(key)))
+	return iter.settle(dirForward)
 }
 
 // SeekForPrev moves the iterator to the last key that less than or equal
 // to the target key, in contrast with Seek.
-func (iter *Iterator) SeekForPrev(key []byte) {
-	cKey := /*byteToChar(key)*/(*C.char)(unsafe.Pointer(&key[0]))
+// It returns whether such pair exist.
+func (iter *Iterator) SeekForPrev(key []byte) bool {
+	if iter.released() {
+		return false
+	}
+	if iter.slice != nil && iter.slice.Limit != nil && iter.cmp(key, iter.slice.Limit) >= 0 {
+		return iter.Last()
+	}
+	cKey := byteToChar(key)
 	C.rocksdb_iter_seek_for_prev(iter.c, (*C.char)(cKey), C.size_t(len(key)))
+	return iter.settle(dirBackward)
 }
 
 // Err returns nil if no errors happened during iteration, or the actual
-// error otherwise.
+// error otherwise. Moving a released iterator yields ErrIterReleased.
 func (iter *Iterator) Err() error {
+	if iter.err != nil || iter.dir == dirReleased {
+		return iter.err
+	}
 	var cErr *C.char
 	C.rocksdb_iter_get_error(iter.c, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
-func (iter *Iterator) Error() error {
-  return iter.Err()
-}
-
-// Close closes the iterator.
-func (iter *Iterator) Close() {
+// Release releases the iterator and calls the releaser set through
+// SetReleaser. It can be called multiple times without causing error.
+func (iter *Iterator) Release() {
+	if iter.dir == dirReleased {
+		return
+	}
 	C.rocksdb_iter_destroy(iter.c)
 	iter.c = nil
+	iter.dir = dirReleased
+	if iter.cleanup != nil {
+		iter.cleanup()
+		iter.cleanup = nil
+	}
+	if iter.releaser != nil {
+		iter.releaser.Release()
+		iter.releaser = nil
+	}
 }
-
-func (iter *Iterator) Release() {
-	iter.Close()
-}
\ No newline at end of file
@@ -0,0 +1,243 @@
+//go:build !cgo
+// +build !cgo
+
+package iterator
+
+import (
+	"bytes"
+	. "../util"
+)
+
+// Iterator provides a way to seek to specific keys and iterate through
+// the keyspace from that point, as well as access the values of those keys.
+//
+// This is the pure-Go iterator used when the package is built without
+// cgo. It iterates over a fixed, sorted set of key/value pairs and behaves
+// like the native iterator: a new Iterator is not positioned, the first
+// call to Next moves it to the first key, while Prev returns false until
+// the iterator has been moved.
+//
+// An Iterator is not safe for concurrent use, but it is safe to use
+// multiple iterators concurrently.
+type Iterator struct {
+	keys   [][]byte
+	values [][]byte
+	cmp    func(a, b []byte) int
+	pos    int
+	dir    dir
+	err    error
+
+	releaser Releaser
+
+	// cleanup releases resources the iterator refers to. It runs once the
+	// iterator is released.
+	cleanup func()
+}
+
+// NewSliceIterator creates an Iterator over the given key/value pairs.
+// The keys must be sorted in ascending order according to cmp; a nil cmp
+// means bytes.Compare. The slices are not copied and must not be modified
+// while the iterator is in use.
+func NewSliceIterator(keys, values [][]byte, cmp func(a, b []byte) int) *Iterator {
+	if cmp == nil {
+		cmp = bytes.Compare
+	}
+	return &Iterator{keys: keys, values: values, cmp: cmp}
+}
+
+// NewSliceIteratorWithCleanup creates an Iterator over the given key/value
+// pairs which calls cleanup once the iterator has been released.
+func NewSliceIteratorWithCleanup(keys, values [][]byte, cmp func(a, b []byte) int, cleanup func()) *Iterator {
+	iter := NewSliceIterator(keys, values, cmp)
+	iter.cleanup = cleanup
+	return iter
+}
+
+// NewEmptyIterator creates an empty, already released iterator whose
+// Error method returns err.
+func NewEmptyIterator(err error) *Iterator {
+	return &Iterator{dir: dirReleased, err: err}
+}
+
+// Valid returns whether the iterator is positioned at a key/value pair.
+// It returns false on a new, exhausted or released iterator.
+func (iter *Iterator) Valid() bool {
+	switch iter.dir {
+	case dirForward, dirBackward:
+		return iter.pos >= 0 && iter.pos < len(iter.keys)
+	}
+	return false
+}
+
+// Key returns the key the iterator currently holds, or nil if the
+// iterator is not positioned at a key. The returned slice is a copy owned
+// by the caller.
+func (iter *Iterator) Key() []byte {
+	if !iter.Valid() {
+		return nil
+	}
+	return append([]byte{}, iter.keys[iter.pos]...)
+}
+
+// Value returns the value in the database the iterator currently holds,
+// or nil if the iterator is not positioned at a key. The returned slice
+// is a copy owned by the caller.
+func (iter *Iterator) Value() []byte {
+	if !iter.Valid() {
+		return nil
+	}
+	return append([]byte{}, iter.values[iter.pos]...)
+}
+
+// KeyUnsafe is like Key but returns the key without copying it. The slice
+// must not be modified, and is only valid until the iterator is moved or
+// released.
+func (iter *Iterator) KeyUnsafe() []byte {
+	if !iter.Valid() {
+		return nil
+	}
+	return iter.keys[iter.pos]
+}
+
+// ValueUnsafe is like Value but returns the value without copying it. The
+// slice must not be modified, and is only valid until the iterator is
+// moved or released.
+func (iter *Iterator) ValueUnsafe() []byte {
+	if !iter.Valid() {
+		return nil
+	}
+	return iter.values[iter.pos]
+}
+
+// settle moves the iterator to pos, records the direction it moved in and
+// reports whether it landed on a key. Running off either end leaves it at
+// that end, so that moving back in the opposite direction restarts from
+// there.
+func (iter *Iterator) settle(pos int, d dir) bool {
+	iter.pos = pos
+	if pos >= 0 && pos < len(iter.keys) {
+		iter.dir = d
+		return true
+	}
+	if d == dirForward {
+		iter.dir = dirEOI
+	} else {
+		iter.dir = dirSOI
+	}
+	return false
+}
+
+// search returns the index of the first key greater than or equal to key.
+func (iter *Iterator) search(key []byte) int {
+	lo, hi := 0, len(iter.keys)
+	for lo < hi {
+		h := int(uint(lo+hi) >> 1)
+		if iter.cmp(iter.keys[h], key) < 0 {
+			lo = h + 1
+		} else {
+			hi = h
+		}
+	}
+	return lo
+}
+
+// First moves the iterator to the first key/value pair. If the iterator
+// only contains one key/value pair then First and Last would moves
+// to the same key/value pair.
+// It returns whether such pair exist.
+func (iter *Iterator) First() bool {
+	if iter.released() {
+		return false
+	}
+	return iter.settle(0, dirForward)
+}
+
+// Last moves the iterator to the last key/value pair. If the iterator
+// only contains one key/value pair then First and Last would moves
+// to the same key/value pair.
+// It returns whether such pair exist.
+func (iter *Iterator) Last() bool {
+	if iter.released() {
+		return false
+	}
+	return iter.settle(len(iter.keys)-1, dirBackward)
+}
+
+// Next moves the iterator to the next key/value pair. A new iterator is
+// moved to the first key/value pair.
+// It returns false if the iterator is exhausted.
+func (iter *Iterator) Next() bool {
+	switch {
+	case iter.released():
+		return false
+	case iter.dir == dirSOI:
+		return iter.First()
+	case iter.dir == dirEOI:
+		return false
+	}
+	return iter.settle(iter.pos+1, dirForward)
+}
+
+// Prev moves the iterator to the previous key/value pair. An iterator
+// exhausted by Next is moved to the last key/value pair.
+// It returns false if the iterator is exhausted.
+func (iter *Iterator) Prev() bool {
+	switch {
+	case iter.released():
+		return false
+	case iter.dir == dirSOI:
+		return false
+	case iter.dir == dirEOI:
+		return iter.Last()
+	}
+	return iter.settle(iter.pos-1, dirBackward)
+}
+
+// Seek moves the iterator to the first key/value pair whose key is greater
+// than or equal to the given key.
+// It returns whether such pair exist.
+func (iter *Iterator) Seek(key []byte) bool {
+	if iter.released() {
+		return false
+	}
+	return iter.settle(iter.search(key), dirForward)
+}
+
+// SeekForPrev moves the iterator to the last key that less than or equal
+// to the target key, in contrast with Seek.
+// It returns whether such pair exist.
+func (iter *Iterator) SeekForPrev(key []byte) bool {
+	if iter.released() {
+		return false
+	}
+	i := iter.search(key)
+	if i == len(iter.keys) || iter.cmp(iter.keys[i], key) != 0 {
+		i--
+	}
+	return iter.settle(i, dirBackward)
+}
+
+// Err returns nil if no errors happened during iteration, or the actual
+// error otherwise. Moving a released iterator yields ErrIterReleased.
+func (iter *Iterator) Err() error {
+	return iter.err
+}
+
+// Release releases the iterator and calls the releaser set through
+// SetReleaser. It can be called multiple times without causing error.
+func (iter *Iterator) Release() {
+	if iter.dir == dirReleased {
+		return
+	}
+	iter.keys = nil
+	iter.values = nil
+	iter.dir = dirReleased
+	if iter.cleanup != nil {
+		iter.cleanup()
+		iter.cleanup = nil
+	}
+	if iter.releaser != nil {
+		iter.releaser.Release()
+		iter.releaser = nil
+	}
+}
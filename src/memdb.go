@@ -0,0 +1,330 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"./errors"
+	"./iterator"
+	"./opt"
+	"./storage"
+	. "./util"
+)
+
+// DB is a reusable handle to a database, created by OpenDB or OpenFile.
+//
+// This is the pure-Go DB used when the package is built without cgo, for
+// unit tests and tooling on machines without the RocksDB library. It keeps
+// the whole database in an in-memory sorted map and offers the goleveldb
+// style subset of the API: Get, Has, Put, Delete, Write, NewIterator,
+// GetSnapshot and OpenTransaction. Nothing is ever written to disk.
+type DB struct {
+	t        *memTable
+	name     string
+	o        *opt.Options
+	readOnly bool
+	closed   uint32
+
+	// storLock and closer are set when the DB is opened on a storage.
+	storLock storage.Locker
+	closer   io.Closer
+}
+
+// memAttacher is implemented by storages that hold the contents of a DB
+// themselves, such as the one returned by storage.NewMemStorage.
+type memAttacher interface {
+	Attach(create func() interface{}) interface{}
+}
+
+// memFiles holds the contents of the DBs opened by path, so that a DB
+// reopened within the same process finds its previous contents.
+var memFiles = struct {
+	sync.Mutex
+	m map[string]*memTable
+}{m: make(map[string]*memTable)}
+
+// attachMemTable returns the table holding the contents of the DB on stor.
+func attachMemTable(stor storage.Storage) (*memTable, error) {
+	if a, ok := stor.(memAttacher); ok {
+		return a.Attach(func() interface{} { return new(memTable) }).(*memTable), nil
+	}
+	path, err := filepath.Abs(stor.Path())
+	if err != nil {
+		return nil, err
+	}
+	memFiles.Lock()
+	defer memFiles.Unlock()
+	t, ok := memFiles.m[path]
+	if !ok {
+		t = new(memTable)
+		memFiles.m[path] = t
+	}
+	return t, nil
+}
+
+// OpenDB opens or creates a DB for the given storage.
+// The DB will be created if not exist, unless ErrorIfMissing is true.
+// Also, if ErrorIfExist is true and the DB exist OpenDB will returns
+// an error. If ReadOnly is true, or the storage is read-only, the DB is
+// opened for read-only access and writes fail with ErrReadOnly.
+//
+// Without cgo the contents of the DB live in memory only: they are held
+// by a memory storage until it is closed, or by the process for storages
+// opened by path. Options other than the comparer and the ones above have
+// no effect.
+//
+// OpenDB holds the storage lock until the DB is closed, so the storage
+// can only be used by one DB at a time. Closing the DB does not close
+// the storage.
+func OpenDB(stor storage.Storage, o *opt.Options) (db *DB, err error) {
+	lock, err := stor.Lock()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			lock.Unlock()
+		}
+	}()
+	t, err := attachMemTable(stor)
+	if err != nil {
+		return nil, err
+	}
+	readOnly := o.GetReadOnly() || stor.ReadOnly()
+	cmp := o.GetComparer()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	switch {
+	case t.exists && o.GetErrorIfExist():
+		return nil, errors.FromStatus("Invalid argument: " + stor.Path() + ": exists (error_if_exists is true)")
+	case !t.exists && (o.GetErrorIfMissing() || readOnly):
+		return nil, errors.FromStatus("Invalid argument: " + stor.Path() + ": does not exist (create_if_missing is false)")
+	case t.exists && t.cmp.Name() != cmp.Name():
+		return nil, errors.FromStatus("Invalid argument: " + cmp.Name() + ": does not match existing comparator " + t.cmp.Name())
+	}
+	if !t.exists {
+		t.cmp = cmp
+		t.exists = true
+	}
+	db = &DB{
+		t:        t,
+		name:     stor.Path(),
+		readOnly: readOnly,
+		storLock: lock,
+	}
+	if o != nil {
+		oc := *o
+		db.o = &oc
+	}
+	return db, nil
+}
+
+// OpenFile opens or creates a DB for the given path, see OpenDB.
+// OpenFile locks the path so any subsequent attempt to open it fails with
+// storage.ErrLocked until the DB is closed. Without cgo only the lock file
+// is written; the contents are kept in memory for the life of the process.
+func OpenFile(path string, o *opt.Options) (db *DB, err error) {
+	stor, err := storage.OpenFile(path, o.GetReadOnly())
+	if err != nil {
+		return nil, err
+	}
+	db, err = OpenDB(stor, o)
+	if err != nil {
+		stor.Close()
+		return nil, err
+	}
+	db.closer = stor
+	return db, nil
+}
+
+// Name returns the name of the database.
+func (db *DB) Name() string {
+	return db.name
+}
+
+// IsReadOnly reports whether the DB was opened read-only. Writes to such a
+// DB fail with ErrReadOnly.
+func (db *DB) IsReadOnly() bool {
+	return db.readOnly
+}
+
+func (db *DB) ok() error {
+	if atomic.LoadUint32(&db.closed) != 0 {
+		return errors.ErrClosed
+	}
+	return nil
+}
+
+func (db *DB) writable() error {
+	if err := db.ok(); err != nil {
+		return err
+	}
+	if db.readOnly {
+		return errors.ErrReadOnly
+	}
+	return nil
+}
+
+// Get returns the data associated with the key from the database.
+// It returns ErrNotFound if the DB does not contain the key.
+//
+// The returned slice is a copy, it is safe to modify its contents.
+func (db *DB) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+	if err := db.ok(); err != nil {
+		return nil, err
+	}
+	return db.get(key, db.t.lastSeq())
+}
+
+func (db *DB) get(key []byte, seq uint64) ([]byte, error) {
+	value, ok := db.t.get(key, seq)
+	if !ok {
+		return nil, errors.ErrNotFound
+	}
+	return append([]byte{}, value...), nil
+}
+
+// Has returns true if the DB does contain the given key.
+func (db *DB) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	if err := db.ok(); err != nil {
+		return false, err
+	}
+	_, ok := db.t.get(key, db.t.lastSeq())
+	return ok, nil
+}
+
+// MultiGet returns the data associated with each of the keys. The values
+// and errors are in the order of the keys; a key the DB does not contain
+// has a nil value and ErrNotFound.
+func (db *DB) MultiGet(ro *opt.ReadOptions, keys [][]byte) ([][]byte, []error) {
+	values := make([][]byte, len(keys))
+	errs := make([]error, len(keys))
+	if err := db.ok(); err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return values, errs
+	}
+	seq := db.t.lastSeq()
+	for i, key := range keys {
+		values[i], errs[i] = db.get(key, seq)
+	}
+	return values, errs
+}
+
+// Put writes data associated with a key to the database.
+// It is safe to modify the contents of the arguments after Put returns.
+func (db *DB) Put(key, value []byte, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	db.t.apply([]memOp{{
+		typ:   WriteBatchValueRecord,
+		key:   append([]byte{}, key...),
+		value: append([]byte{}, value...),
+	}})
+	return nil
+}
+
+// Delete removes the data associated with the key from the database.
+// It is safe to modify the contents of the argument after Delete returns.
+func (db *DB) Delete(key []byte, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	db.t.apply([]memOp{{
+		typ: WriteBatchDeletionRecord,
+		key: append([]byte{}, key...),
+	}})
+	return nil
+}
+
+// SingleDelete removes the data associated with the key from the database.
+// It is meant for keys that were put once and never overwritten; without
+// cgo it behaves exactly like Delete.
+func (db *DB) SingleDelete(key []byte, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	db.t.apply([]memOp{{
+		typ: WriteBatchSingleDeletionRecord,
+		key: append([]byte{}, key...),
+	}})
+	return nil
+}
+
+// DeleteRange removes the keys within r from the database, r.Start
+// included and r.Limit excluded. A nil Limit extends the range to the
+// last key of the database.
+func (db *DB) DeleteRange(wo *opt.WriteOptions, r Range) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	op := memOp{
+		typ: WriteBatchRangeDeletion,
+		key: append([]byte{}, r.Start...),
+	}
+	if r.Limit != nil {
+		op.value = append([]byte{}, r.Limit...)
+	}
+	db.t.apply([]memOp{op})
+	return nil
+}
+
+// DeletePrefix removes the keys starting with prefix from the database,
+// see DeleteRange. Without cgo there is nothing to compact, so compact
+// has no effect.
+func (db *DB) DeletePrefix(wo *opt.WriteOptions, prefix []byte, compact bool) error {
+	return db.DeleteRange(wo, *BytesPrefix(prefix))
+}
+
+// Write applies the given batch to the DB atomically. Batches holding
+// records other than puts, deletions and range deletions of the default
+// column family are rejected as a whole with ErrNotSupported.
+func (db *DB) Write(batch *Batch, wo *opt.WriteOptions) error {
+	if err := db.writable(); err != nil {
+		return err
+	}
+	ops, err := batch.ops()
+	if err != nil {
+		return err
+	}
+	db.t.apply(ops)
+	return nil
+}
+
+// NewIterator returns an Iterator over the the database, limited to the
+// keys within slice; a nil slice or a nil Limit leaves that side
+// unbounded. The iterator sees the DB as it was when it was created.
+func (db *DB) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	if err := db.ok(); err != nil {
+		return iterator.NewEmptyIterator(err)
+	}
+	return db.newIterator(slice, db.t.lastSeq())
+}
+
+func (db *DB) newIterator(slice *Range, seq uint64) *iterator.Iterator {
+	keys, values := db.t.collect(slice, seq)
+	return iterator.NewSliceIterator(keys, values, db.t.cmp.Compare)
+}
+
+// Close closes the DB. Closing the DB does not release its snapshots and
+// iterators, which keep working on the contents they saw.
+func (db *DB) Close() error {
+	if !atomic.CompareAndSwapUint32(&db.closed, 0, 1) {
+		return errors.ErrClosed
+	}
+	if db.storLock != nil {
+		db.storLock.Unlock()
+	}
+	if db.closer != nil {
+		return db.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	"encoding/binary"
+
+	"./errors"
+)
+
+// Batch is a goleveldb style write batch. The zero value is ready to use.
+//
+// Without cgo the batch is kept in Go memory, in the same RocksDB
+// WriteBatch wire format as the native batch, so that Dump and Load are
+// compatible between both builds.
+type Batch struct {
+	data []byte
+}
+
+// NewBatch creates a Batch object.
+func NewBatch() *Batch {
+	b := new(Batch)
+	b.init()
+	return b
+}
+
+func (b *Batch) init() {
+	if len(b.data) < batchHeaderLen {
+		b.data = make([]byte, batchHeaderLen)
+	}
+}
+
+func (b *Batch) appendRecord(t WriteBatchRecordType, key, value []byte, hasValue bool) {
+	b.init()
+	b.data = append(b.data, byte(t))
+	b.data = appendSlice(b.data, key)
+	if hasValue {
+		b.data = appendSlice(b.data, value)
+	}
+	binary.LittleEndian.PutUint32(b.data[8:batchHeaderLen], uint32(b.Len()+1))
+}
+
+func appendSlice(dst, b []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(b)))
+	return append(append(dst, buf[:n]...), b...)
+}
+
+// Put appends 'put operation' of the given key/value pair to the batch.
+// It is safe to modify the contents of the argument after Put returns.
+func (wb *Batch) Put(key, value []byte) {
+	wb.appendRecord(WriteBatchValueRecord, key, value, true)
+}
+
+// Delete appends 'delete operation' of the given key to the batch.
+// It is safe to modify the contents of the argument after Delete returns.
+func (wb *Batch) Delete(key []byte) {
+	wb.appendRecord(WriteBatchDeletionRecord, key, nil, false)
+}
+
+// SingleDelete appends 'single delete operation' of the given key to the
+// batch, see DB.SingleDelete.
+// It is safe to modify the contents of the argument after SingleDelete returns.
+func (wb *Batch) SingleDelete(key []byte) {
+	wb.appendRecord(WriteBatchSingleDeletionRecord, key, nil, false)
+}
+
+// Dump dumps batch contents in the RocksDB WriteBatch wire format. The
+// returned slice is a copy and can be passed to Load.
+func (wb *Batch) Dump() []byte {
+	wb.init()
+	return append([]byte(nil), wb.data...)
+}
+
+// Load loads given slice into the batch. Previous contents of the batch
+// will be discarded. The records are validated before anything is
+// replaced, and the slice is copied.
+func (wb *Batch) Load(data []byte) error {
+	if err := validBatch(data); err != nil {
+		return err
+	}
+	wb.data = append(wb.data[:0], data...)
+	return nil
+}
+
+// Replay replays batch contents. Puts are handed to r.Put, deletions and
+// single deletions to r.Delete. Records of the default column family
+// without a goleveldb equivalent, and records of other column families,
+// are skipped.
+func (wb *Batch) Replay(r BatchReplay) error {
+	iter := wb.newIterator()
+	for iter.Next() {
+		switch rec := iter.Record(); rec.Type {
+		case WriteBatchValueRecord:
+			r.Put(rec.Key, rec.Value)
+		case
+			WriteBatchDeletionRecord,
+			WriteBatchSingleDeletionRecord:
+			r.Delete(rec.Key)
+		}
+	}
+	return iter.Error()
+}
+
+// ops returns the updates of the batch, in order, as applied by DB.Write.
+// The keys and values are copies.
+func (wb *Batch) ops() ([]memOp, error) {
+	var ops []memOp
+	iter := wb.newIterator()
+	for iter.Next() {
+		rec := iter.Record()
+		switch rec.Type {
+		case
+			WriteBatchValueRecord,
+			WriteBatchDeletionRecord,
+			WriteBatchSingleDeletionRecord,
+			WriteBatchRangeDeletion:
+			ops = append(ops, memOp{
+				typ:   rec.Type,
+				key:   append([]byte{}, rec.Key...),
+				value: append([]byte{}, rec.Value...),
+			})
+		case
+			WriteBatchLogDataRecord,
+			WriteBatchNoopRecord:
+		default:
+			return nil, errors.FromStatus("Not implemented: unsupported batch record type")
+		}
+	}
+	return ops, iter.Error()
+}
+
+func (wb *Batch) newIterator() *WriteBatchIterator {
+	if len(wb.data) < batchHeaderLen {
+		return &WriteBatchIterator{}
+	}
+	return &WriteBatchIterator{data: wb.data[batchHeaderLen:]}
+}
+
+// Len returns number of records in the batch.
+func (wb *Batch) Len() int {
+	if len(wb.data) < batchHeaderLen {
+		return 0
+	}
+	return int(binary.LittleEndian.Uint32(wb.data[8:batchHeaderLen]))
+}
+
+// Reset resets the batch.
+func (wb *Batch) Reset() {
+	if wb.data != nil {
+		wb.data = wb.data[:0]
+		wb.init()
+	}
+}
+
+// Destroy releases the memory held by the batch. The Batch may be reused
+// afterwards.
+func (wb *Batch) Destroy() {
+	wb.data = nil
+}
@@ -0,0 +1,103 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	"fmt"
+	"sync"
+
+	"./errors"
+	"./iterator"
+	"./opt"
+	. "./util"
+)
+
+// Snapshot provides a consistent view of read operations in a DB.
+//
+// A Snapshot obtained through DB.GetSnapshot can be read directly; it is
+// safe for concurrent use and must be released once no longer needed.
+type Snapshot struct {
+	db       *DB
+	seq      uint64
+	mu       sync.RWMutex
+	released bool
+}
+
+// GetSnapshot returns a latest snapshot of the underlying DB. A snapshot
+// is a frozen snapshot of a DB state at a particular point in time. The
+// content of snapshot are guaranteed to be consistent.
+//
+// The snapshot must be released after use, by calling Release method.
+func (db *DB) GetSnapshot() (*Snapshot, error) {
+	if err := db.ok(); err != nil {
+		return nil, err
+	}
+	return &Snapshot{db: db, seq: db.t.acquire()}, nil
+}
+
+// Get gets the value for the given key. It returns ErrNotFound if
+// the DB does not contains the key.
+//
+// The returned slice is a copy, it is safe to modify its contents.
+func (snap *Snapshot) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+	snap.mu.RLock()
+	defer snap.mu.RUnlock()
+	if snap.released {
+		return nil, errors.ErrSnapshotReleased
+	}
+	return snap.db.get(key, snap.seq)
+}
+
+// Has returns true if the DB does contains the given key.
+//
+// It is safe to modify the contents of the argument after Has returns.
+func (snap *Snapshot) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	snap.mu.RLock()
+	defer snap.mu.RUnlock()
+	if snap.released {
+		return false, errors.ErrSnapshotReleased
+	}
+	_, ok := snap.db.t.get(key, snap.seq)
+	return ok, nil
+}
+
+// NewIterator returns an iterator for the snapshot of the underlying DB,
+// limited to the keys within slice as with DB.NewIterator.
+// Releasing the snapshot does not release the iterator; the iterator must
+// be released on its own.
+//
+// If the snapshot has been released the iterator is empty and its Error
+// method returns ErrSnapshotReleased.
+func (snap *Snapshot) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	snap.mu.RLock()
+	defer snap.mu.RUnlock()
+	if snap.released {
+		return iterator.NewEmptyIterator(errors.ErrSnapshotReleased)
+	}
+	return snap.db.newIterator(slice, snap.seq)
+}
+
+// SequenceNumber returns the sequence number of the snapshot.
+func (snap *Snapshot) SequenceNumber() uint64 {
+	return snap.seq
+}
+
+// Release releases the snapshot. This will not release any returned
+// iterators, the iterators would still be valid until released.
+//
+// Other methods should not be called after the snapshot has been released.
+// Release can be called multiple times without causing error.
+func (snap *Snapshot) Release() {
+	snap.mu.Lock()
+	defer snap.mu.Unlock()
+	if snap.released {
+		return
+	}
+	snap.released = true
+	snap.db.t.release(snap.seq)
+}
+
+func (snap *Snapshot) String() string {
+	return fmt.Sprintf("rocksdb.Snapshot{%d}", snap.seq)
+}
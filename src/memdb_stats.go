@@ -0,0 +1,52 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	. "./util"
+)
+
+// Stats populates s with database statistics. Without cgo there are no
+// levels, tables or compactions; only AliveSnapshots and MemTableSize,
+// the size of all the keys and values held, are filled.
+func (db *DB) Stats(s *DBStats) error {
+	if err := db.ok(); err != nil {
+		return err
+	}
+	s.reset()
+	t := db.t
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	for _, n := range t.snaps {
+		s.AliveSnapshots += int32(n)
+	}
+	for _, e := range t.entries {
+		for _, v := range e.versions {
+			s.MemTableSize += len(e.key) + len(v.value)
+		}
+	}
+	return nil
+}
+
+// SizeOf calculates the sizes of the given key ranges, as the size of the
+// keys and values they hold. As with goleveldb, a range whose Limit is not
+// greater than its Start, such as one with a nil Limit, has size zero.
+func (db *DB) SizeOf(ranges []Range) (Sizes, error) {
+	if err := db.ok(); err != nil {
+		return nil, err
+	}
+	t := db.t
+	seq := t.lastSeq()
+	sizes := make(Sizes, len(ranges))
+	for i := range ranges {
+		if t.cmp.Compare(ranges[i].Start, ranges[i].Limit) >= 0 {
+			continue
+		}
+		keys, values := t.collect(&ranges[i], seq)
+		for j := range keys {
+			sizes[i] += int64(len(keys[j]) + len(values[j]))
+		}
+	}
+	return sizes, nil
+}
@@ -0,0 +1,212 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	"sync"
+
+	"./comparer"
+	. "./util"
+)
+
+// memVersion is a value of a key as written at a sequence number.
+type memVersion struct {
+	seq     uint64
+	value   []byte
+	deleted bool
+}
+
+// memEntry holds the versions of a key still visible to the DB or one of
+// its snapshots, oldest first.
+type memEntry struct {
+	key      []byte
+	versions []memVersion
+}
+
+// visible returns the newest version of the entry written at or before seq.
+func (e *memEntry) visible(seq uint64) *memVersion {
+	for i := len(e.versions) - 1; i >= 0; i-- {
+		if e.versions[i].seq <= seq {
+			return &e.versions[i]
+		}
+	}
+	return nil
+}
+
+// memOp is a single update applied to a memTable.
+type memOp struct {
+	typ   WriteBatchRecordType
+	key   []byte
+	value []byte
+}
+
+// memTable is the sorted, multi-versioned map holding the contents of a DB
+// built without cgo. Every update gets its own sequence number, like the
+// records of a RocksDB WriteBatch, and the versions a live snapshot can
+// still see are kept until the snapshot is released.
+type memTable struct {
+	mu      sync.RWMutex
+	cmp     comparer.Comparer
+	entries []*memEntry
+	seq     uint64
+	snaps   map[uint64]int
+	exists  bool
+}
+
+// search returns the index of the first entry whose key is greater than
+// or equal to key, and whether that entry has the key.
+func (t *memTable) search(key []byte) (int, bool) {
+	lo, hi := 0, len(t.entries)
+	for lo < hi {
+		h := int(uint(lo+hi) >> 1)
+		if t.cmp.Compare(t.entries[h].key, key) < 0 {
+			lo = h + 1
+		} else {
+			hi = h
+		}
+	}
+	return lo, lo < len(t.entries) && t.cmp.Compare(t.entries[lo].key, key) == 0
+}
+
+// get returns the value of key as of seq.
+func (t *memTable) get(key []byte, seq uint64) ([]byte, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	i, ok := t.search(key)
+	if !ok {
+		return nil, false
+	}
+	v := t.entries[i].visible(seq)
+	if v == nil || v.deleted {
+		return nil, false
+	}
+	return v.value, true
+}
+
+// lastSeq returns the sequence number of the latest update.
+func (t *memTable) lastSeq() uint64 {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.seq
+}
+
+// collect returns the keys within slice and their values as of seq. The
+// returned slices are shared with the table and must not be modified.
+func (t *memTable) collect(slice *Range, seq uint64) (keys, values [][]byte) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	i, end := t.bounds(slice)
+	for ; i < end; i++ {
+		e := t.entries[i]
+		if v := e.visible(seq); v != nil && !v.deleted {
+			keys = append(keys, e.key)
+			values = append(values, v.value)
+		}
+	}
+	return keys, values
+}
+
+// bounds returns the range of entries within slice.
+func (t *memTable) bounds(slice *Range) (start, end int) {
+	end = len(t.entries)
+	if slice == nil {
+		return 0, end
+	}
+	if slice.Start != nil {
+		start, _ = t.search(slice.Start)
+	}
+	if slice.Limit != nil {
+		end, _ = t.search(slice.Limit)
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+// apply applies the given updates, each at the next sequence number.
+func (t *memTable) apply(ops []memOp) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, op := range ops {
+		t.seq++
+		switch op.typ {
+		case WriteBatchValueRecord:
+			t.set(op.key, memVersion{seq: t.seq, value: op.value})
+		case WriteBatchDeletionRecord, WriteBatchSingleDeletionRecord:
+			t.set(op.key, memVersion{seq: t.seq, deleted: true})
+		case WriteBatchRangeDeletion:
+			i, end := t.bounds(&Range{Start: op.key, Limit: op.value})
+			keys := make([][]byte, 0, end-i)
+			for ; i < end; i++ {
+				keys = append(keys, t.entries[i].key)
+			}
+			for _, key := range keys {
+				t.set(key, memVersion{seq: t.seq, deleted: true})
+			}
+		}
+	}
+}
+
+// set adds a version of key, dropping the versions no longer visible.
+func (t *memTable) set(key []byte, v memVersion) {
+	i, ok := t.search(key)
+	if !ok {
+		if v.deleted {
+			return
+		}
+		t.entries = append(t.entries, nil)
+		copy(t.entries[i+1:], t.entries[i:])
+		t.entries[i] = &memEntry{key: key}
+	}
+	e := t.entries[i]
+	e.versions = append(e.versions, v)
+	oldest, ok := t.oldestSnapshot()
+	if !ok {
+		oldest = t.seq
+	}
+	j := len(e.versions) - 1
+	for j > 0 && e.versions[j].seq > oldest {
+		j--
+	}
+	e.versions = append(e.versions[:0], e.versions[j:]...)
+	if len(e.versions) == 1 && e.versions[0].deleted {
+		t.entries = append(t.entries[:i], t.entries[i+1:]...)
+	}
+}
+
+// oldestSnapshot returns the sequence number of the oldest live snapshot.
+func (t *memTable) oldestSnapshot() (uint64, bool) {
+	var (
+		oldest uint64
+		ok     bool
+	)
+	for seq := range t.snaps {
+		if !ok || seq < oldest {
+			oldest, ok = seq, true
+		}
+	}
+	return oldest, ok
+}
+
+// acquire pins the versions visible at the latest sequence number and
+// returns that sequence number.
+func (t *memTable) acquire() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.snaps == nil {
+		t.snaps = make(map[uint64]int)
+	}
+	t.snaps[t.seq]++
+	return t.seq
+}
+
+// release unpins the versions pinned by acquire.
+func (t *memTable) release(seq uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.snaps[seq]--; t.snaps[seq] <= 0 {
+		delete(t.snaps, seq)
+	}
+}
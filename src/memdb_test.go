@@ -0,0 +1,340 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"./errors"
+	"./opt"
+	"./storage"
+	"./util"
+)
+
+func newTestMemDB(t *testing.T) *DB {
+	db, err := OpenDB(storage.NewMemStorage(), nil)
+	ensure.Nil(t, err)
+	return db
+}
+
+func TestMemDBCRUD(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	var (
+		givenKey  = []byte("hello")
+		givenVal1 = []byte("world1")
+		givenVal2 = []byte("world2")
+	)
+
+	ensure.Nil(t, db.Put(givenKey, givenVal1, nil))
+	v1, err := db.Get(givenKey, nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v1, givenVal1)
+
+	ensure.Nil(t, db.Put(givenKey, givenVal2, nil))
+	v2, err := db.Get(givenKey, nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v2, givenVal2)
+	has, err := db.Has(givenKey, nil)
+	ensure.Nil(t, err)
+	ensure.True(t, has)
+
+	ensure.Nil(t, db.Delete(givenKey, nil))
+	_, err = db.Get(givenKey, nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	has, err = db.Has(givenKey, nil)
+	ensure.Nil(t, err)
+	ensure.False(t, has)
+
+	ensure.Nil(t, db.Close())
+	_, err = db.Get(givenKey, nil)
+	ensure.DeepEqual(t, err, errors.ErrClosed)
+}
+
+func TestMemDBBatch(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("k1"), []byte("v0"), nil))
+	b := NewBatch()
+	b.Put([]byte("k1"), []byte("v1"))
+	b.Put([]byte("k2"), []byte("v2"))
+	b.Delete([]byte("k1"))
+	ensure.DeepEqual(t, b.Len(), 3)
+
+	var loaded Batch
+	ensure.Nil(t, loaded.Load(b.Dump()))
+	ensure.DeepEqual(t, loaded.Len(), 3)
+	ensure.Nil(t, db.Write(&loaded, nil))
+
+	_, err := db.Get([]byte("k1"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	v2, err := db.Get([]byte("k2"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v2, []byte("v2"))
+
+	b.Reset()
+	ensure.DeepEqual(t, b.Len(), 0)
+	ensure.NotNil(t, loaded.Load([]byte("short")))
+}
+
+func TestMemDBSnapshot(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	snap, err := db.GetSnapshot()
+	ensure.Nil(t, err)
+	ensure.Nil(t, db.Put([]byte("a"), []byte("2"), nil))
+	ensure.Nil(t, db.Put([]byte("b"), []byte("3"), nil))
+
+	v, err := snap.Get([]byte("a"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("1"))
+	has, err := snap.Has([]byte("b"), nil)
+	ensure.Nil(t, err)
+	ensure.False(t, has)
+
+	iter := snap.NewIterator(nil, nil)
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	iter.Release()
+	ensure.DeepEqual(t, keys, []string{"a"})
+
+	snap.Release()
+	_, err = snap.Get([]byte("a"), nil)
+	ensure.DeepEqual(t, err, ErrSnapshotReleased)
+	v, err = db.Get([]byte("a"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("2"))
+}
+
+func TestMemDBIterator(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	for _, k := range []string{"a1", "b1", "b2", "b3", "c1"} {
+		ensure.Nil(t, db.Put([]byte(k), []byte("v"+k), nil))
+	}
+
+	iter := db.NewIterator(util.BytesPrefix([]byte("b")), nil)
+	defer iter.Release()
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	ensure.DeepEqual(t, keys, []string{"b1", "b2", "b3"})
+	ensure.True(t, iter.Prev())
+	ensure.DeepEqual(t, iter.Key(), []byte("b3"))
+	ensure.DeepEqual(t, iter.Value(), []byte("vb3"))
+	ensure.DeepEqual(t, iter.KeyUnsafe(), []byte("b3"))
+	ensure.DeepEqual(t, iter.ValueUnsafe(), []byte("vb3"))
+	key := iter.Key()
+	key[0] = 'x'
+	ensure.DeepEqual(t, iter.KeyUnsafe(), []byte("b3"))
+
+	ensure.True(t, iter.Seek([]byte("b15")))
+	ensure.DeepEqual(t, iter.Key(), []byte("b2"))
+	ensure.True(t, iter.SeekForPrev([]byte("b15")))
+	ensure.DeepEqual(t, iter.Key(), []byte("b1"))
+	ensure.False(t, iter.Seek([]byte("c")))
+	ensure.True(t, iter.Prev())
+	ensure.DeepEqual(t, iter.Key(), []byte("b3"))
+
+	iter.Release()
+	ensure.False(t, iter.Next())
+	ensure.DeepEqual(t, iter.Error(), util.ErrIterReleased)
+}
+
+func TestMemDBTransaction(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	ensure.Nil(t, db.Put([]byte("c"), []byte("3"), nil))
+
+	tr, err := db.OpenTransaction()
+	ensure.Nil(t, err)
+	ensure.Nil(t, tr.Put([]byte("b"), []byte("2"), nil))
+	ensure.Nil(t, tr.Delete([]byte("c"), nil))
+
+	v, err := tr.Get([]byte("b"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("2"))
+	_, err = tr.Get([]byte("c"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	_, err = db.Get([]byte("b"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	iter := tr.NewIterator(nil, nil)
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	ensure.DeepEqual(t, keys, []string{"a", "b"})
+
+	ensure.Nil(t, tr.Commit())
+	ensure.False(t, iter.Next())
+	v, err = db.Get([]byte("b"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("2"))
+	has, err := db.Has([]byte("c"), nil)
+	ensure.Nil(t, err)
+	ensure.False(t, has)
+
+	// batches the transaction cannot apply are rejected as a whole
+	tr, err = db.OpenTransaction()
+	ensure.Nil(t, err)
+	defer tr.Discard()
+	var b Batch
+	ensure.Nil(t, b.Load([]byte{
+		0, 0, 0, 0, 0, 0, 0, 0, 2, 0, 0, 0,
+		byte(WriteBatchValueRecord), 1, 'x', 1, '1',
+		byte(WriteBatchMergeRecord), 1, 'x', 1, '2',
+	}))
+	ensure.True(t, errors.Is(tr.Write(&b, nil), errors.ErrNotSupported))
+	_, err = tr.Get([]byte("x"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+}
+
+func TestMemDBOpenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rocksdb-TestMemDBOpenFile")
+	ensure.Nil(t, err)
+
+	db, err := OpenFile(dir, nil)
+	ensure.Nil(t, err)
+	_, err = OpenFile(dir, nil)
+	ensure.DeepEqual(t, err, storage.ErrLocked)
+	ensure.Nil(t, db.Put([]byte("foo"), []byte("bar"), nil))
+	ensure.Nil(t, db.Close())
+
+	_, err = OpenFile(dir, &opt.Options{ErrorIfExist: true})
+	ensure.True(t, errors.Is(err, errors.ErrInvalidArgument))
+
+	db, err = OpenFile(dir, &opt.Options{ReadOnly: true})
+	ensure.Nil(t, err)
+	defer db.Close()
+	v, err := db.Get([]byte("foo"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("bar"))
+	ensure.True(t, db.IsReadOnly())
+	ensure.DeepEqual(t, db.Put([]byte("foo"), []byte("baz"), nil), errors.ErrReadOnly)
+	ensure.DeepEqual(t, db.DeletePrefix(nil, []byte("f"), false), errors.ErrReadOnly)
+}
+
+func TestMemDBStatsSizeOf(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a1"), []byte("12345"), nil))
+	ensure.Nil(t, db.Put([]byte("b1"), []byte("123"), nil))
+	snap, err := db.GetSnapshot()
+	ensure.Nil(t, err)
+	defer snap.Release()
+
+	var stats DBStats
+	ensure.Nil(t, db.Stats(&stats))
+	ensure.DeepEqual(t, stats.AliveSnapshots, int32(1))
+	ensure.DeepEqual(t, stats.MemTableSize, 12)
+
+	sizes, err := db.SizeOf([]util.Range{
+		{Start: []byte("a"), Limit: []byte("b")},
+		{Start: []byte("a")},
+		{Limit: []byte("c")},
+	})
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, sizes, Sizes{7, 0, 12})
+	ensure.DeepEqual(t, sizes.Sum(), int64(19))
+}
+
+func TestMemDBEmptyKeysAndValues(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	cases := []struct {
+		name       string
+		key, value []byte
+	}{
+		{"empty value", []byte("key"), []byte{}},
+		{"nil value", []byte("key"), nil},
+		{"empty key", []byte{}, []byte("value")},
+		{"nil key", nil, []byte("value")},
+		{"empty key and value", []byte{}, []byte{}},
+	}
+	for _, c := range cases {
+		ensure.Nil(t, db.Put(c.key, c.value, nil), c.name)
+		v, err := db.Get(c.key, nil)
+		ensure.Nil(t, err, c.name)
+		ensure.DeepEqual(t, string(v), string(c.value), c.name)
+
+		iter := db.NewIterator(nil, nil)
+		ensure.True(t, iter.Seek(c.key), c.name)
+		ensure.DeepEqual(t, string(iter.Key()), string(c.key), c.name)
+		ensure.True(t, iter.SeekForPrev(c.key), c.name)
+		ensure.DeepEqual(t, string(iter.Value()), string(c.value), c.name)
+		iter.Release()
+
+		ensure.Nil(t, db.Delete(c.key, nil), c.name)
+		_, err = db.Get(c.key, nil)
+		ensure.DeepEqual(t, err, ErrNotFound, c.name)
+	}
+}
+
+func TestMemDBMultiGet(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	ensure.Nil(t, db.Put([]byte("c"), []byte{}, nil))
+
+	values, errs := db.MultiGet(nil, [][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	ensure.DeepEqual(t, values, [][]byte{[]byte("1"), nil, {}})
+	ensure.DeepEqual(t, errs, []error{nil, ErrNotFound, nil})
+}
+
+func TestMemDBDeleteRange(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	for _, k := range []string{"a", "t1/a", "t1/b", "t2/a", "t2/b", "z"} {
+		ensure.Nil(t, db.Put([]byte(k), []byte("v"), nil))
+	}
+	keys := func() []string {
+		iter := db.NewIterator(nil, nil)
+		defer iter.Release()
+		keys := []string{}
+		for iter.Next() {
+			keys = append(keys, string(iter.Key()))
+		}
+		return keys
+	}
+
+	ensure.Nil(t, db.DeleteRange(nil, util.Range{Start: []byte("t2/a"), Limit: []byte("t2/b")}))
+	ensure.DeepEqual(t, keys(), []string{"a", "t1/a", "t1/b", "t2/b", "z"})
+	ensure.Nil(t, db.DeletePrefix(nil, []byte("t1/"), true))
+	ensure.DeepEqual(t, keys(), []string{"a", "t2/b", "z"})
+	ensure.Nil(t, db.DeleteRange(nil, util.Range{Start: []byte("t")}))
+	ensure.DeepEqual(t, keys(), []string{"a"})
+}
+
+func TestMemDBSingleDelete(t *testing.T) {
+	db := newTestMemDB(t)
+	defer db.Close()
+
+	ensure.Nil(t, db.Put([]byte("a"), []byte("1"), nil))
+	ensure.Nil(t, db.Put([]byte("b"), []byte("2"), nil))
+	ensure.Nil(t, db.SingleDelete([]byte("a"), nil))
+	b := NewBatch()
+	b.SingleDelete([]byte("b"))
+	ensure.Nil(t, db.Write(b, nil))
+	for _, key := range []string{"a", "b"} {
+		_, err := db.Get([]byte(key), nil)
+		ensure.DeepEqual(t, err, ErrNotFound)
+	}
+}
@@ -0,0 +1,260 @@
+//go:build !cgo
+// +build !cgo
+
+package rocksdb
+
+import (
+	"sync"
+
+	"./errors"
+	"./iterator"
+	"./opt"
+	. "./constants"
+	. "./util"
+)
+
+var (
+	errTransactionDone    = errors.New(PkgName + ": transaction already closed")
+	errBatchNotReplayable = errors.FromStatus("Not implemented: transactions only apply puts and deletions of the default column family")
+)
+
+// DBTransaction is the goleveldb style transaction handle of a DB, created
+// by DB.OpenTransaction. Writes are buffered in a Batch, are visible to
+// reads through the transaction, and are written to the DB atomically by
+// Commit.
+//
+// Unlike goleveldb, an open transaction does not block other writers; it
+// provides read-your-writes and an atomic commit, not isolation.
+type DBTransaction struct {
+	db    *DB
+	batch Batch
+
+	// pending holds the latest buffered write of each key, sorted by key.
+	pending []memVersionOf
+
+	mu    sync.Mutex
+	iters []*iterator.Iterator
+	done  bool
+}
+
+// memVersionOf is a buffered write of a key.
+type memVersionOf struct {
+	key []byte
+	memVersion
+}
+
+// OpenTransaction opens an atomic DB transaction. The returned transaction
+// must be closed, either by Commit or by Discard.
+func (db *DB) OpenTransaction() (*DBTransaction, error) {
+	if err := db.writable(); err != nil {
+		return nil, err
+	}
+	return &DBTransaction{db: db}, nil
+}
+
+// search returns the index of the first pending write whose key is greater
+// than or equal to key, and whether that write is for the key.
+func (tr *DBTransaction) search(key []byte) (int, bool) {
+	cmp := tr.db.t.cmp
+	lo, hi := 0, len(tr.pending)
+	for lo < hi {
+		h := int(uint(lo+hi) >> 1)
+		if cmp.Compare(tr.pending[h].key, key) < 0 {
+			lo = h + 1
+		} else {
+			hi = h
+		}
+	}
+	return lo, lo < len(tr.pending) && cmp.Compare(tr.pending[lo].key, key) == 0
+}
+
+// Get gets the value for the given key, looking at the pending writes of
+// the transaction first. It returns ErrNotFound if neither the transaction
+// nor the DB contains the key.
+func (tr *DBTransaction) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return nil, errTransactionDone
+	}
+	if i, ok := tr.search(key); ok {
+		if tr.pending[i].deleted {
+			return nil, errors.ErrNotFound
+		}
+		return append([]byte{}, tr.pending[i].value...), nil
+	}
+	return tr.db.Get(key, ro)
+}
+
+// Has returns true if the transaction or the DB contains the given key.
+func (tr *DBTransaction) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	_, err := tr.Get(key, ro)
+	switch err {
+	case nil:
+		return true, nil
+	case errors.ErrNotFound:
+		return false, nil
+	}
+	return false, err
+}
+
+// Put sets the value for the given key within the transaction. wo is
+// accepted for goleveldb compatibility; Commit decides how the write is
+// persisted.
+func (tr *DBTransaction) Put(key, value []byte, wo *opt.WriteOptions) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	tr.put(key, value)
+	return nil
+}
+
+func (tr *DBTransaction) put(key, value []byte) {
+	tr.batch.Put(key, value)
+	tr.set(key, memVersion{value: append([]byte{}, value...)})
+}
+
+// Delete deletes the value for the given key within the transaction.
+func (tr *DBTransaction) Delete(key []byte, wo *opt.WriteOptions) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	tr.delete(key)
+	return nil
+}
+
+func (tr *DBTransaction) delete(key []byte) {
+	tr.batch.Delete(key)
+	tr.set(key, memVersion{deleted: true})
+}
+
+func (tr *DBTransaction) set(key []byte, v memVersion) {
+	i, ok := tr.search(key)
+	if ok {
+		tr.pending[i].memVersion = v
+		return
+	}
+	tr.pending = append(tr.pending, memVersionOf{})
+	copy(tr.pending[i+1:], tr.pending[i:])
+	tr.pending[i] = memVersionOf{key: append([]byte{}, key...), memVersion: v}
+}
+
+// transactionReplay applies replayed batch records to a transaction.
+type transactionReplay struct {
+	tr *DBTransaction
+}
+
+func (r transactionReplay) Put(key, value []byte) { r.tr.put(key, value) }
+func (r transactionReplay) Delete(key []byte)     { r.tr.delete(key) }
+
+// Write applies the puts and deletes of the given batch to the transaction.
+// Batches holding other records, such as merges, range deletions or
+// updates of other column families, are rejected as a whole with
+// ErrNotSupported.
+func (tr *DBTransaction) Write(batch *Batch, wo *opt.WriteOptions) error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	ok, err := replayable(batch.newIterator())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errBatchNotReplayable
+	}
+	return batch.Replay(transactionReplay{tr})
+}
+
+// NewIterator returns an iterator over the DB with the pending writes of the
+// transaction merged in, limited to the keys within slice as with
+// DB.NewIterator. The iterator is released when the transaction is closed.
+func (tr *DBTransaction) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return iterator.NewEmptyIterator(errTransactionDone)
+	}
+	if err := tr.db.ok(); err != nil {
+		return iterator.NewEmptyIterator(err)
+	}
+	t := tr.db.t
+	baseKeys, baseValues := t.collect(slice, t.lastSeq())
+	start, end := 0, len(tr.pending)
+	if slice != nil && slice.Start != nil {
+		start, _ = tr.search(slice.Start)
+	}
+	if slice != nil && slice.Limit != nil {
+		end, _ = tr.search(slice.Limit)
+	}
+	var keys, values [][]byte
+	for i, j := 0, start; i < len(baseKeys) || j < end; {
+		c := -1
+		switch {
+		case i == len(baseKeys):
+			c = 1
+		case j < end:
+			c = t.cmp.Compare(baseKeys[i], tr.pending[j].key)
+		}
+		if c < 0 {
+			keys = append(keys, baseKeys[i])
+			values = append(values, baseValues[i])
+			i++
+			continue
+		}
+		if p := tr.pending[j]; !p.deleted {
+			keys = append(keys, p.key)
+			values = append(values, p.value)
+		}
+		if c == 0 {
+			i++
+		}
+		j++
+	}
+	iter := iterator.NewSliceIterator(keys, values, t.cmp.Compare)
+	tr.iters = append(tr.iters, iter)
+	return iter
+}
+
+// Commit commits the transaction. If error is not nil, then the transaction
+// is not committed, it can then either be retried or discarded.
+//
+// Other methods should not be called after transaction has been committed.
+func (tr *DBTransaction) Commit() error {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if tr.done {
+		return errTransactionDone
+	}
+	if err := tr.db.Write(&tr.batch, nil); err != nil {
+		return err
+	}
+	tr.close()
+	return nil
+}
+
+// Discard discards the transaction.
+//
+// Other methods should not be called after transaction has been discarded.
+func (tr *DBTransaction) Discard() {
+	tr.mu.Lock()
+	defer tr.mu.Unlock()
+	if !tr.done {
+		tr.close()
+	}
+}
+
+func (tr *DBTransaction) close() {
+	for _, iter := range tr.iters {
+		iter.Release()
+	}
+	tr.iters = nil
+	tr.batch.Destroy()
+	tr.pending = nil
+	tr.done = true
+}
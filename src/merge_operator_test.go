@@ -1,9 +1,14 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	"./opt"
+	"./util"
 	. "./constants"
 )
 
@@ -27,18 +32,17 @@ func TestMergeOperator(t *testing.T) {
 	})
 	defer db.Close()
 
-	wo := NewDefaultWriteOptions()
-	ensure.Nil(t, db.Put(wo, givenKey, givenVal1))
-	ensure.Nil(t, db.Merge(wo, givenKey, givenVal2))
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put(givenKey, givenVal1, wo))
+	ensure.Nil(t, db.Merge(NewDefaultWriteOptions(), givenKey, givenVal2))
 
 	// trigger a compaction to ensure that a merge is performed
-	db.CompactRange(Range{nil, nil})
+	db.CompactRange(util.Range{})
 
-	ro := NewDefaultReadOptions()
-	v1, err := db.Get(ro, givenKey)
-	defer v1.Free()
+	ro := &opt.ReadOptions{}
+	v1, err := db.Get(givenKey, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v1.Data(), givenMerged)
+	ensure.DeepEqual(t, v1, givenMerged)
 }
 
 type mockMergeOperator struct {
@@ -0,0 +1,96 @@
+package rocksdb
+
+//#include "api.h"
+//#include <stdlib.h>
+//#include <string.h>
+import "C"
+import (
+	"unsafe"
+	"./errors"
+	"./opt"
+)
+
+// cKeyList copies keys into a single C buffer, as cgo does not allow
+// passing Go pointers stored in Go memory. The returned function frees it.
+func cKeyList(keys [][]byte) ([]*C.char, []C.size_t, func()) {
+	size := 0
+	for _, key := range keys {
+		size += len(key)
+	}
+	cBuf := (*C.char)(C.malloc(C.size_t(size + 1)))
+	cKeys := make([]*C.char, len(keys))
+	cKeySizes := make([]C.size_t, len(keys))
+	off := 0
+	for i, key := range keys {
+		cKeys[i] = (*C.char)(unsafe.Pointer(uintptr(unsafe.Pointer(cBuf)) + uintptr(off)))
+		cKeySizes[i] = C.size_t(len(key))
+		if len(key) > 0 {
+			C.memcpy(unsafe.Pointer(cKeys[i]), unsafe.Pointer(&key[0]), C.size_t(len(key)))
+		}
+		off += len(key)
+	}
+	return cKeys, cKeySizes, func() { C.free(unsafe.Pointer(cBuf)) }
+}
+
+// multiGetResults converts and frees the values and errors returned by the
+// native multi get functions. Keys that were not found get a nil value and
+// ErrNotFound.
+func multiGetResults(cValues []*C.char, cValueSizes []C.size_t, cErrs []*C.char) ([][]byte, []error) {
+	values := make([][]byte, len(cValues))
+	errs := make([]error, len(cValues))
+	for i := range cValues {
+		switch {
+		case cErrs[i] != nil:
+			errs[i] = errors.FromStatus(C.GoString(cErrs[i]))
+			C.free(unsafe.Pointer(cErrs[i]))
+		case cValues[i] == nil:
+			errs[i] = errors.ErrNotFound
+		default:
+			values[i] = C.GoBytes(unsafe.Pointer(cValues[i]), C.int(cValueSizes[i]))
+		}
+		if cValues[i] != nil {
+			C.free(unsafe.Pointer(cValues[i]))
+		}
+	}
+	return values, errs
+}
+
+// MultiGet returns the data associated with each of the keys, in a single
+// call to RocksDB. The values and errors are in the order of the keys; a
+// key the DB does not contain has a nil value and ErrNotFound.
+//
+// It is safe to modify the contents of the argument after MultiGet returns.
+func (db *DB) MultiGet(ro *opt.ReadOptions, keys [][]byte) ([][]byte, []error) {
+	if len(keys) == 0 {
+		return [][]byte{}, []error{}
+	}
+	cKeys, cKeySizes, free := cKeyList(keys)
+	defer free()
+	var (
+		cValues     = make([]*C.char, len(keys))
+		cValueSizes = make([]C.size_t, len(keys))
+		cErrs       = make([]*C.char, len(keys))
+	)
+	C.rocksdb_multi_get(db.c, db.readOptions(ro).c, C.size_t(len(keys)), &cKeys[0], &cKeySizes[0], &cValues[0], &cValueSizes[0], &cErrs[0])
+	return multiGetResults(cValues, cValueSizes, cErrs)
+}
+
+// MultiGetCF is like MultiGet but looks the keys up in the column family.
+func (db *DB) MultiGetCF(opts *ReadOptions, cf *ColumnFamilyHandle, keys [][]byte) ([][]byte, []error) {
+	if len(keys) == 0 {
+		return [][]byte{}, []error{}
+	}
+	cKeys, cKeySizes, free := cKeyList(keys)
+	defer free()
+	var (
+		cCFs        = make([]*C.rocksdb_column_family_handle_t, len(keys))
+		cValues     = make([]*C.char, len(keys))
+		cValueSizes = make([]C.size_t, len(keys))
+		cErrs       = make([]*C.char, len(keys))
+	)
+	for i := range cCFs {
+		cCFs[i] = cf.c
+	}
+	C.rocksdb_multi_get_cf(db.c, opts.c, &cCFs[0], C.size_t(len(keys)), &cKeys[0], &cKeySizes[0], &cValues[0], &cValueSizes[0], &cErrs[0])
+	return multiGetResults(cValues, cValueSizes, cErrs)
+}
@@ -3,7 +3,11 @@ package rocksdb
 //#include "api.h"
 //#include "stdlib.h"
 import "C"
-import "unsafe"
+import (
+	"unsafe"
+	"./comparer"
+	"./opt"
+)
 
 // CompressionType specifies the block compression.
 // DB contents are stored in a set of blocks, each of which holds a
@@ -62,9 +66,16 @@ type Options struct {
 	// Hold references for GC.
 	env  *Env
 	bbto *BlockBasedTableOptions
+	// ownBbto is set when bbto and its block cache were created by
+	// newOptionsFromOpt, they are destroyed with the Options.
+	ownBbto bool
 
 	// We keep these so we can free their memory in Destroy.
 	ccmp *C.rocksdb_comparator_t
+	// gocmp is set when ccmp is a Go comparator created by api_comparator_create.
+	gocmp bool
+	// cmp is the Go comparator of ccmp, if gocmp is set.
+	cmp  Comparator
 	cmo  *C.rocksdb_mergeoperator_t
 	cst  *C.rocksdb_slicetransform_t
 	ccf  *C.rocksdb_compactionfilter_t
@@ -88,9 +99,13 @@ func NewNativeOptions(c *C.rocksdb_options_t) *Options {
 func (opts *Options) SetComparator(value Comparator) {
 	if nc, ok := value.(nativeComparator); ok {
 		opts.ccmp = nc.c
+		opts.gocmp = false
+		opts.cmp = nil
 	} else {
 		idx := registerComperator(value)
 		opts.ccmp = C.api_comparator_create(C.uintptr_t(idx))
+		opts.gocmp = true
+		opts.cmp = value
 	}
 	C.rocksdb_options_set_comparator(opts.c, opts.ccmp)
 }
@@ -1045,10 +1060,55 @@ func (opts *Options) SetAllowIngestBehind(value bool) {
 	C.rocksdb_options_set_allow_ingest_behind(opts.c, boolToChar(value))
 }
 
+// newOptionsFromOpt translates goleveldb style options into the equivalent
+// RocksDB configuration. A nil o yields the goleveldb defaults.
+func newOptionsFromOpt(o *opt.Options) *Options {
+	opts := NewDefaultOptions()
+	opts.SetCreateIfMissing(!o.GetErrorIfMissing())
+	opts.SetErrorIfExists(o.GetErrorIfExist())
+	opts.SetParanoidChecks(o.GetStrict(opt.StrictManifest))
+	opts.SetSkipLogErrorOnRecovery(!o.GetStrict(opt.StrictJournal))
+	opts.SetMaxOpenFiles(o.GetOpenFilesCacheCapacity())
+	opts.SetWriteBufferSize(o.GetWriteBuffer())
+	opts.SetLevel0FileNumCompactionTrigger(o.GetCompactionL0Trigger())
+	opts.SetLevel0SlowdownWritesTrigger(o.GetWriteL0SlowdownTrigger())
+	opts.SetLevel0StopWritesTrigger(o.GetWriteL0PauseTrigger())
+	opts.SetTargetFileSizeBase(uint64(o.GetCompactionTableSize()))
+	opts.SetTargetFileSizeMultiplier(o.GetCompactionTableSizeMultiplier())
+	opts.SetMaxBytesForLevelBase(uint64(o.GetCompactionTotalSize()))
+	opts.SetMaxBytesForLevelMultiplier(o.GetCompactionTotalSizeMultiplier())
+	if cmp := o.GetComparer(); cmp.Name() != comparer.BytewiseComparatorName {
+		opts.SetComparator(cmp)
+	}
+	switch o.GetCompression() {
+	case opt.NoCompression:
+		opts.SetCompression(NoCompression)
+	default:
+		opts.SetCompression(SnappyCompression)
+	}
+
+	bbto := NewDefaultBlockBasedTableOptions()
+	bbto.SetBlockSize(o.GetBlockSize())
+	bbto.SetBlockRestartInterval(o.GetBlockRestartInterval())
+	if capacity := o.GetBlockCacheCapacity(); capacity > 0 && !o.GetDisableBlockCache() {
+		bbto.SetBlockCache(NewLRUCache(capacity))
+	} else {
+		bbto.SetNoBlockCache(true)
+	}
+	if f := o.GetFilter(); f != nil {
+		bbto.SetFilterPolicy(NewFilterPolicy(f, o.GetAltFilters()...))
+	}
+	opts.SetBlockBasedTableFactory(bbto)
+	opts.ownBbto = true
+	return opts
+}
+
 // Destroy deallocates the Options object.
 func (opts *Options) Destroy() {
 	C.rocksdb_options_destroy(opts.c)
-	if opts.ccmp != nil {
+	if opts.ccmp != nil && opts.gocmp {
+		C.api_comparator_destroy(opts.ccmp)
+	} else if opts.ccmp != nil {
 		C.rocksdb_comparator_destroy(opts.ccmp)
 	}
 	if opts.cst != nil {
@@ -1057,7 +1117,17 @@ func (opts *Options) Destroy() {
 	if opts.ccf != nil {
 		C.rocksdb_compactionfilter_destroy(opts.ccf)
 	}
+	if opts.ownBbto {
+		// The filter policy is owned by the table options and released
+		// with them.
+		cache := opts.bbto.cache
+		opts.bbto.Destroy()
+		if cache != nil {
+			cache.Destroy()
+		}
+	}
 	opts.c = nil
 	opts.env = nil
 	opts.bbto = nil
+	opts.ownBbto = false
 }
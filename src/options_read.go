@@ -1,8 +1,12 @@
 package rocksdb
 
 //#include "api.h"
+//#include <stdlib.h>
 import "C"
-import "unsafe"
+import (
+	"unsafe"
+	. "./util"
+)
 
 // ReadTier controls fetching of data during a read request.
 // An application can issue a read request (via Get/Iterators) and specify
@@ -24,6 +28,15 @@ const (
 // database.
 type ReadOptions struct {
 	c *C.rocksdb_readoptions_t
+
+	// Go-side copies of the settings. The C API has no getters, so these
+	// are needed to derive per-call copies, see copy.
+	verifyChecksums bool
+	fillCache       bool
+	snapshot        *Snapshot
+	readTier        ReadTier
+	tailing         bool
+	pinData         bool
 }
 
 // NewDefaultReadOptions creates a default ReadOptions object.
@@ -33,7 +46,7 @@ func NewDefaultReadOptions() *ReadOptions {
 
 // NewNativeReadOptions creates a ReadOptions object.
 func NewNativeReadOptions(c *C.rocksdb_readoptions_t) *ReadOptions {
-	return &ReadOptions{c}
+	return &ReadOptions{c: c, verifyChecksums: true, fillCache: true}
 }
 
 // UnsafeGetReadOptions returns the underlying c read options object.
@@ -43,8 +56,9 @@ func (opts *ReadOptions) UnsafeGetReadOptions() unsafe.Pointer {
 
 // SetVerifyChecksums speciy if all data read from underlying storage will be
 // verified against corresponding checksums.
-// Default: false
+// Default: true
 func (opts *ReadOptions) SetVerifyChecksums(value bool) {
+	opts.verifyChecksums = value
 	C.rocksdb_readoptions_set_verify_checksums(opts.c, boolToChar(value))
 }
 
@@ -53,6 +67,7 @@ func (opts *ReadOptions) SetVerifyChecksums(value bool) {
 // Callers may wish to set this field to false for bulk scans.
 // Default: true
 func (opts *ReadOptions) SetFillCache(value bool) {
+	opts.fillCache = value
 	C.rocksdb_readoptions_set_fill_cache(opts.c, boolToChar(value))
 }
 
@@ -61,6 +76,7 @@ func (opts *ReadOptions) SetFillCache(value bool) {
 // not have been released.
 // Default: nil
 func (opts *ReadOptions) SetSnapshot(snap *Snapshot) {
+	opts.snapshot = snap
 	C.rocksdb_readoptions_set_snapshot(opts.c, snap.c)
 }
 
@@ -69,6 +85,7 @@ func (opts *ReadOptions) SetSnapshot(snap *Snapshot) {
 // found at the specified cache, then Status::Incomplete is returned.
 // Default: ReadAllTier
 func (opts *ReadOptions) SetReadTier(value ReadTier) {
+	opts.readTier = value
 	C.rocksdb_readoptions_set_read_tier(opts.c, C.int(value))
 }
 
@@ -79,6 +96,7 @@ func (opts *ReadOptions) SetReadTier(value ReadTier) {
 // that were inserted into the database after the creation of the iterator.
 // Default: false
 func (opts *ReadOptions) SetTailing(value bool) {
+	opts.tailing = value
 	C.rocksdb_readoptions_set_tailing(opts.c, boolToChar(value))
 }
 
@@ -98,6 +116,17 @@ func (opts *ReadOptions) SetIterateUpperBound(key []byte) {
 	C.rocksdb_readoptions_set_iterate_upper_bound(opts.c, cKey, cKeyLen)
 }
 
+// SetIterateLowerBound specifies "iterate_lower_bound", which defines
+// the smallest key at which the backward iterator can return an entry.
+// Once the bound is passed, Valid() will be false.
+// "iterate_lower_bound" is inclusive ie the bound value is a valid entry.
+// Default: nullptr
+func (opts *ReadOptions) SetIterateLowerBound(key []byte) {
+	cKey := byteToChar(key)
+	cKeyLen := C.size_t(len(key))
+	C.rocksdb_readoptions_set_iterate_lower_bound(opts.c, cKey, cKeyLen)
+}
+
 // SetPinData specifies the value of "pin_data". If true, it keeps the blocks
 // loaded by the iterator pinned in memory as long as the iterator is not deleted,
 // If used when reading from tables created with
@@ -106,6 +135,7 @@ func (opts *ReadOptions) SetIterateUpperBound(key []byte) {
 // return 1.
 // Default: false
 func (opts *ReadOptions) SetPinData(value bool) {
+	opts.pinData = value
 	C.rocksdb_readoptions_set_pin_data(opts.c, boolToChar(value))
 }
 
@@ -114,3 +144,46 @@ func (opts *ReadOptions) Destroy() {
 	C.rocksdb_readoptions_destroy(opts.c)
 	opts.c = nil
 }
+
+// copy returns a new ReadOptions object with the same settings as opts,
+// apart from the iterate bounds.
+func (opts *ReadOptions) copy() *ReadOptions {
+	ro := NewDefaultReadOptions()
+	ro.SetVerifyChecksums(opts.verifyChecksums)
+	ro.SetFillCache(opts.fillCache)
+	if opts.snapshot != nil {
+		ro.SetSnapshot(opts.snapshot)
+	}
+	ro.SetReadTier(opts.readTier)
+	ro.SetTailing(opts.tailing)
+	ro.SetPinData(opts.pinData)
+	return ro
+}
+
+// withBounds returns a copy of opts whose iterate bounds are the Start and
+// Limit of r. RocksDB keeps pointers to both the bound keys and the options
+// object, so the keys are copied to C memory and the returned function,
+// which frees the copy and the keys, must only be called once the iterator
+// created with it has been destroyed. A nil Start or Limit is unbounded.
+func (opts *ReadOptions) withBounds(r *Range) (*ReadOptions, func()) {
+	ro := opts.copy()
+
+	var cStart, cLimit *C.char
+	if len(r.Start) > 0 {
+		cStart = cByteSlice(r.Start)
+		C.rocksdb_readoptions_set_iterate_lower_bound(ro.c, cStart, C.size_t(len(r.Start)))
+	}
+	if r.Limit != nil {
+		// An empty, non-nil Limit is an empty range; RocksDB treats a nil
+		// pointer as unbounded, so always hand it an allocation.
+		if cLimit = cByteSlice(r.Limit); cLimit == nil {
+			cLimit = (*C.char)(C.malloc(1))
+		}
+		C.rocksdb_readoptions_set_iterate_upper_bound(ro.c, cLimit, C.size_t(len(r.Limit)))
+	}
+	return ro, func() {
+		ro.Destroy()
+		C.free(unsafe.Pointer(cStart))
+		C.free(unsafe.Pointer(cLimit))
+	}
+}
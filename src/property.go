@@ -0,0 +1,48 @@
+package rocksdb
+
+// Names of the well-known database properties.
+//
+// The string properties hold human readable reports, read them with
+// GetProperty. The cfstats and dbstats properties can also be read as maps
+// with GetMapProperty.
+const (
+	PropertyStats      = "rocksdb.stats"
+	PropertySSTables   = "rocksdb.sstables"
+	PropertyCFStats    = "rocksdb.cfstats"
+	PropertyDBStats    = "rocksdb.dbstats"
+	PropertyLevelStats = "rocksdb.levelstats"
+
+	// PropertyNumFilesAtLevelPrefix is followed by the level number, as
+	// in "rocksdb.num-files-at-level0".
+	PropertyNumFilesAtLevelPrefix = "rocksdb.num-files-at-level"
+)
+
+// Names of the integer database properties, read them with GetIntProperty.
+const (
+	PropertyNumImmutableMemTable           = "rocksdb.num-immutable-mem-table"
+	PropertyMemTableFlushPending           = "rocksdb.mem-table-flush-pending"
+	PropertyCompactionPending              = "rocksdb.compaction-pending"
+	PropertyBackgroundErrors               = "rocksdb.background-errors"
+	PropertyCurSizeActiveMemTable          = "rocksdb.cur-size-active-mem-table"
+	PropertyCurSizeAllMemTables            = "rocksdb.cur-size-all-mem-tables"
+	PropertySizeAllMemTables               = "rocksdb.size-all-mem-tables"
+	PropertyNumEntriesActiveMemTable       = "rocksdb.num-entries-active-mem-table"
+	PropertyNumDeletesActiveMemTable       = "rocksdb.num-deletes-active-mem-table"
+	PropertyEstimateNumKeys                = "rocksdb.estimate-num-keys"
+	PropertyEstimateTableReadersMem        = "rocksdb.estimate-table-readers-mem"
+	PropertyIsFileDeletionsEnabled         = "rocksdb.is-file-deletions-enabled"
+	PropertyNumSnapshots                   = "rocksdb.num-snapshots"
+	PropertyOldestSnapshotTime             = "rocksdb.oldest-snapshot-time"
+	PropertyNumLiveVersions                = "rocksdb.num-live-versions"
+	PropertyEstimateLiveDataSize           = "rocksdb.estimate-live-data-size"
+	PropertyTotalSSTFilesSize              = "rocksdb.total-sst-files-size"
+	PropertyLiveSSTFilesSize               = "rocksdb.live-sst-files-size"
+	PropertyEstimatePendingCompactionBytes = "rocksdb.estimate-pending-compaction-bytes"
+	PropertyNumRunningCompactions          = "rocksdb.num-running-compactions"
+	PropertyNumRunningFlushes              = "rocksdb.num-running-flushes"
+	PropertyActualDelayedWriteRate         = "rocksdb.actual-delayed-write-rate"
+	PropertyIsWriteStopped                 = "rocksdb.is-write-stopped"
+	PropertyBlockCacheCapacity             = "rocksdb.block-cache-capacity"
+	PropertyBlockCacheUsage                = "rocksdb.block-cache-usage"
+	PropertyBlockCachePinnedUsage          = "rocksdb.block-cache-pinned-usage"
+)
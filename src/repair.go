@@ -0,0 +1,177 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"./opt"
+	"./storage"
+)
+
+// RepairReport describes what a repair did to the files of a DB. It is
+// built by comparing the table files live in the repaired DB with the
+// listings of the DB directory and of its lost/ directory, where the
+// repair moves the files it drops, before and after the repair. File names
+// are relative to the DB directory, or to its lost/ directory for
+// LostFiles.
+type RepairReport struct {
+	// TablesSalvaged lists the tables that were kept by the repair, and
+	// are live in the repaired DB.
+	TablesSalvaged []string
+
+	// TablesCreated lists the live tables of the repaired DB that did not
+	// exist before, which the repair created from the WAL files.
+	TablesCreated []string
+
+	// TablesLost lists the tables that are not live in the repaired DB,
+	// because they could not be read. The repair moves them to the lost/
+	// directory.
+	TablesLost []string
+
+	// WALsReplayed lists the non-empty WAL files the repair read. Their
+	// readable records are in TablesCreated, and the files themselves are
+	// moved to the lost/ directory.
+	WALsReplayed []string
+
+	// WALsDropped lists the empty WAL files, which had nothing to replay.
+	// They are moved to the lost/ directory as well.
+	WALsDropped []string
+
+	// LostFiles lists the files the repair moved to the lost/ directory.
+	LostFiles []string
+}
+
+// dbFiles is a listing of the files of a DB directory.
+type dbFiles struct {
+	tables map[string]int64
+	wals   map[string]int64
+	lost   map[string]bool
+}
+
+func listDBFiles(path string) (*dbFiles, error) {
+	files := &dbFiles{
+		tables: make(map[string]int64),
+		wals:   make(map[string]int64),
+		lost:   make(map[string]bool),
+	}
+	fis, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, fi := range fis {
+		switch name := fi.Name(); {
+		case strings.HasSuffix(name, ".sst"), strings.HasSuffix(name, ".ldb"):
+			files.tables[name] = fi.Size()
+		case strings.HasSuffix(name, ".log"):
+			files.wals[name] = fi.Size()
+		}
+	}
+	fis, err = ioutil.ReadDir(filepath.Join(path, "lost"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, fi := range fis {
+		files.lost[fi.Name()] = true
+	}
+	return files, nil
+}
+
+// newRepairReport builds the report of a repair from the listings of the
+// DB directory before and after it, and the live files of the repaired DB.
+func newRepairReport(before, after *dbFiles, live []LiveFileMetadata) *RepairReport {
+	report := &RepairReport{}
+	liveTables := make(map[string]bool, len(live))
+	for _, f := range live {
+		name := filepath.Base(f.Name)
+		liveTables[name] = true
+		if _, ok := before.tables[name]; !ok {
+			report.TablesCreated = append(report.TablesCreated, name)
+		}
+	}
+	for name := range before.tables {
+		if liveTables[name] {
+			report.TablesSalvaged = append(report.TablesSalvaged, name)
+		} else {
+			report.TablesLost = append(report.TablesLost, name)
+		}
+	}
+	for name, size := range before.wals {
+		if _, ok := after.wals[name]; ok {
+			// Left in place, so it was not part of the repair.
+			continue
+		}
+		if size > 0 {
+			report.WALsReplayed = append(report.WALsReplayed, name)
+		} else {
+			report.WALsDropped = append(report.WALsDropped, name)
+		}
+	}
+	for name := range after.lost {
+		if !before.lost[name] {
+			report.LostFiles = append(report.LostFiles, name)
+		}
+	}
+	for _, names := range [][]string{
+		report.TablesSalvaged,
+		report.TablesCreated,
+		report.TablesLost,
+		report.WALsReplayed,
+		report.WALsDropped,
+		report.LostFiles,
+	} {
+		sort.Strings(names)
+	}
+	return report
+}
+
+// RecoverFile recovers and opens a DB with missing or corrupted manifest
+// files for the given path, see RepairDb. It returns a report of what the
+// repair did alongside the DB. ErrorIfExist is ignored, while the other
+// options are used both for the repair and for opening the DB.
+//
+// The DB must be closed after use, by calling Close method.
+func RecoverFile(path string, o *opt.Options) (db *DB, report *RepairReport, err error) {
+	stor, err := storage.OpenFile(path, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			stor.Close()
+		}
+	}()
+
+	ro := &opt.Options{}
+	if o != nil {
+		*ro = *o
+	}
+	ro.ErrorIfExist = false
+
+	before, err := listDBFiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts := newOptionsFromOpt(ro)
+	err = RepairDb(path, opts)
+	opts.Destroy()
+	if err != nil {
+		return nil, nil, err
+	}
+	after, err := listDBFiles(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err = OpenDB(stor, ro)
+	if err != nil {
+		return nil, nil, err
+	}
+	db.closer = stor
+	return db, newRepairReport(before, after, db.GetLiveFilesMetaData()), nil
+}
@@ -0,0 +1,46 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"./opt"
+	. "./constants"
+)
+
+func TestRecoverFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", PkgName+"-TestRecoverFile")
+	ensure.Nil(t, err)
+
+	db, err := OpenFile(dir, nil)
+	ensure.Nil(t, err)
+	wo := &opt.WriteOptions{}
+	ro := &opt.ReadOptions{}
+	ensure.Nil(t, db.Put([]byte("flushed"), []byte("val1"), wo))
+	ensure.Nil(t, db.Flush(NewDefaultFlushOptions()))
+	ensure.Nil(t, db.Put([]byte("logged"), []byte("val2"), wo))
+	ensure.Nil(t, db.Close())
+	ensure.Nil(t, ioutil.WriteFile(filepath.Join(dir, "999999.sst"), []byte("garbage"), 0644))
+
+	db, report, err := RecoverFile(dir, nil)
+	ensure.Nil(t, err)
+	defer db.Close()
+
+	v, err := db.Get([]byte("flushed"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val1"))
+	v, err = db.Get([]byte("logged"), ro)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val2"))
+
+	ensure.DeepEqual(t, len(report.TablesSalvaged), 1)
+	ensure.DeepEqual(t, report.TablesLost, []string{"999999.sst"})
+	ensure.True(t, len(report.TablesCreated) > 0)
+	ensure.True(t, len(report.WALsReplayed) > 0)
+	ensure.True(t, len(report.LostFiles) > 0)
+}
@@ -0,0 +1,210 @@
+package rocksdb
+
+//#include "api.h"
+//#include <stdlib.h>
+import "C"
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+	"./errors"
+)
+
+// OpenDbAsSecondary opens a secondary instance of the database at name.
+// A secondary instance follows a primary instance that keeps writing to
+// the database, possibly from another process; it only sees the writes
+// of the primary up to the last call to TryCatchUpWithPrimary, see also
+// StartCatchUp. secondaryPath is a directory of its own where the
+// secondary instance keeps its info logs. opts should set
+// max_open_files to -1, and writes to the DB return ErrReadOnly.
+func OpenDbAsSecondary(opts *Options, name, secondaryPath string) (*DB, error) {
+	var (
+		cErr           *C.char
+		cName          = C.CString(name)
+		cSecondaryPath = C.CString(secondaryPath)
+	)
+	defer C.free(unsafe.Pointer(cName))
+	defer C.free(unsafe.Pointer(cSecondaryPath))
+	db := C.rocksdb_open_as_secondary(opts.c, cName, cSecondaryPath, &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
+	}
+	return &DB{
+		name:      name,
+		c:         db,
+		opts:      opts,
+		readOnly:  true,
+		secondary: true,
+	}, nil
+}
+
+// OpenDbAsSecondaryColumnFamilies opens a secondary instance of the
+// database at name with the specified column families, see
+// OpenDbAsSecondary and OpenDbColumnFamilies. Unlike with a primary, the
+// column families may be any subset of the ones of the database.
+func OpenDbAsSecondaryColumnFamilies(
+	opts *Options,
+	name string,
+	secondaryPath string,
+	cfNames []string,
+	cfOpts []*Options,
+) (*DB, []*ColumnFamilyHandle, error) {
+	numColumnFamilies := len(cfNames)
+	if numColumnFamilies != len(cfOpts) {
+		return nil, nil, errors.New("must provide the same number of column family names and options")
+	}
+
+	cName := C.CString(name)
+	defer C.free(unsafe.Pointer(cName))
+	cSecondaryPath := C.CString(secondaryPath)
+	defer C.free(unsafe.Pointer(cSecondaryPath))
+
+	cNames := make([]*C.char, numColumnFamilies)
+	for i, s := range cfNames {
+		cNames[i] = C.CString(s)
+	}
+	defer func() {
+		for _, s := range cNames {
+			C.free(unsafe.Pointer(s))
+		}
+	}()
+
+	cOpts := make([]*C.rocksdb_options_t, numColumnFamilies)
+	for i, o := range cfOpts {
+		cOpts[i] = o.c
+	}
+
+	cHandles := make([]*C.rocksdb_column_family_handle_t, numColumnFamilies)
+
+	var cErr *C.char
+	db := C.rocksdb_open_as_secondary_column_families(
+		opts.c,
+		cName,
+		cSecondaryPath,
+		C.int(numColumnFamilies),
+		&cNames[0],
+		&cOpts[0],
+		&cHandles[0],
+		&cErr,
+	)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return nil, nil, errors.FromStatus(C.GoString(cErr))
+	}
+
+	cfHandles := make([]*ColumnFamilyHandle, numColumnFamilies)
+	d := &DB{
+		name:      name,
+		c:         db,
+		opts:      opts,
+		readOnly:  true,
+		secondary: true,
+	}
+	for i, c := range cHandles {
+		cfHandles[i] = NewNativeColumnFamilyHandle(c)
+		d.cfs.add(cfNames[i], cfHandles[i])
+	}
+	return d, cfHandles, nil
+}
+
+// IsSecondary reports whether the DB is a secondary instance.
+func (db *DB) IsSecondary() bool {
+	return db.secondary
+}
+
+// TryCatchUpWithPrimary makes a secondary instance see the writes the
+// primary made since it was opened or last caught up. It fails with
+// ErrNotSupported on a DB that is not a secondary instance.
+func (db *DB) TryCatchUpWithPrimary() error {
+	var cErr *C.char
+	C.rocksdb_try_catch_up_with_primary(db.c, &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return errors.FromStatus(C.GoString(cErr))
+	}
+	return nil
+}
+
+// catchUp is the state of the background catch up of a secondary DB.
+type catchUp struct {
+	mu   sync.Mutex
+	stop chan struct{}
+
+	// call is held by the catch up goroutine while it catches up, so that
+	// stopping waits for the catch up in progress but not for onError,
+	// which may itself stop the catch up or close the DB.
+	call sync.Mutex
+}
+
+// StartCatchUp starts a goroutine calling TryCatchUpWithPrimary every
+// interval, until StopCatchUp is called or the DB is closed. Errors are
+// passed to onError, which may be nil; they do not stop the catch up.
+// onError may call StopCatchUp, StartCatchUp or Close. Starting the catch
+// up again replaces the previous goroutine. The interval must be
+// positive, and a closed DB returns ErrClosed.
+func (db *DB) StartCatchUp(interval time.Duration, onError func(error)) error {
+	if !db.secondary {
+		return errors.FromStatus("Not implemented: catch up is only supported by secondary instances")
+	}
+	if interval <= 0 {
+		return errors.FromStatus("Invalid argument: catch up interval must be positive")
+	}
+	c := &db.catchUp
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Close marks the DB closed before it stops the catch up, so checking
+	// under the lock keeps a goroutine from outliving the DB.
+	if atomic.LoadUint32(&db.closed) != 0 {
+		return errors.ErrClosed
+	}
+	c.stopLocked()
+	stop := make(chan struct{})
+	c.stop = stop
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+			c.call.Lock()
+			select {
+			case <-stop:
+				// Stopped while the ticker fired.
+				c.call.Unlock()
+				return
+			default:
+			}
+			err := db.TryCatchUpWithPrimary()
+			c.call.Unlock()
+			if err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+	return nil
+}
+
+// StopCatchUp stops the catch up started by StartCatchUp and waits for a
+// catch up in progress to finish; a call to onError in progress may still
+// be running when it returns. It does nothing if no catch up is running.
+func (db *DB) StopCatchUp() {
+	c := &db.catchUp
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.stopLocked()
+}
+
+func (c *catchUp) stopLocked() {
+	if c.stop == nil {
+		return
+	}
+	close(c.stop)
+	c.call.Lock()
+	c.call.Unlock()
+	c.stop = nil
+}
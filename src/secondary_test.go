@@ -0,0 +1,63 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/facebookgo/ensure"
+	. "./constants"
+	"./errors"
+)
+
+func TestOpenDbAsSecondary(t *testing.T) {
+	primary := newTestDB(t, "TestOpenDbAsSecondary", func(opts *Options) {
+		opts.SetMaxOpenFiles(-1)
+	})
+	defer primary.Close()
+	ensure.Nil(t, primary.Put([]byte("a"), []byte("1"), nil))
+	ensure.False(t, primary.IsSecondary())
+	ensure.True(t, errors.Is(primary.StartCatchUp(time.Millisecond, nil), errors.ErrNotSupported))
+
+	dir, err := ioutil.TempDir("", PkgName+"-TestOpenDbAsSecondary-secondary")
+	ensure.Nil(t, err)
+	opts := NewDefaultOptions()
+	opts.SetMaxOpenFiles(-1)
+	db, err := OpenDbAsSecondary(opts, primary.Name(), dir)
+	ensure.Nil(t, err)
+	defer db.Close()
+	ensure.True(t, db.IsSecondary())
+	v, err := db.Get([]byte("a"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("1"))
+	ensure.DeepEqual(t, db.Put([]byte("b"), []byte("2"), nil), errors.ErrReadOnly)
+	ensure.DeepEqual(t, db.Write(NewBatch(), nil), errors.ErrReadOnly)
+
+	ensure.Nil(t, primary.Put([]byte("b"), []byte("2"), nil))
+	_, err = db.Get([]byte("b"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	ensure.Nil(t, db.TryCatchUpWithPrimary())
+	v, err = db.Get([]byte("b"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("2"))
+
+	// The background catch up follows the primary until stopped.
+	ensure.Nil(t, db.StartCatchUp(10*time.Millisecond, func(err error) { t.Error(err) }))
+	ensure.Nil(t, primary.Put([]byte("c"), []byte("3"), nil))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err = db.Get([]byte("c"), nil); err == nil || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	ensure.Nil(t, err)
+	db.StopCatchUp()
+
+	ensure.True(t, errors.Is(db.StartCatchUp(0, nil), errors.ErrInvalidArgument))
+	ensure.Nil(t, db.Close())
+	ensure.DeepEqual(t, db.StartCatchUp(time.Millisecond, nil), errors.ErrClosed)
+}
@@ -1,9 +1,14 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	"./opt"
+	. "./constants"
 )
 
 func TestSliceTransform(t *testing.T) {
@@ -12,12 +17,12 @@ func TestSliceTransform(t *testing.T) {
 	})
 	defer db.Close()
 
-	wo := NewDefaultWriteOptions()
-	ensure.Nil(t, db.Put(wo, []byte("foo1"), []byte("foo")))
-	ensure.Nil(t, db.Put(wo, []byte("foo2"), []byte("foo")))
-	ensure.Nil(t, db.Put(wo, []byte("bar1"), []byte("bar")))
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put([]byte("foo1"), []byte("foo"), wo))
+	ensure.Nil(t, db.Put([]byte("foo2"), []byte("foo"), wo))
+	ensure.Nil(t, db.Put([]byte("bar1"), []byte("bar"), wo))
 
-	iter := db.NewIterator(NewDefaultReadOptions())
+	iter := db.NewIterator(nil, &opt.ReadOptions{})
 	defer iter.Close()
 	prefix := []byte("foo")
 	numFound := 0
@@ -2,13 +2,148 @@ package rocksdb
 
 //#include "api.h"
 import "C"
+import (
+	"fmt"
+	"sync"
+	"./errors"
+	"./iterator"
+	"./opt"
+	. "./util"
+)
 
 // Snapshot provides a consistent view of read operations in a DB.
+//
+// A Snapshot obtained through DB.GetSnapshot can be read directly; it is
+// safe for concurrent use and must be released once no longer needed.
 type Snapshot struct {
 	c *C.rocksdb_snapshot_t
+
+	db       *DB
+	ro       *ReadOptions
+	seq      uint64
+	mu       sync.RWMutex
+	released bool
 }
 
 // NewNativeSnapshot creates a Snapshot object.
 func NewNativeSnapshot(c *C.rocksdb_snapshot_t) *Snapshot {
-	return &Snapshot{c}
-}
\ No newline at end of file
+	return &Snapshot{c: c}
+}
+
+// GetSnapshot returns a latest snapshot of the underlying DB. A snapshot
+// is a frozen snapshot of a DB state at a particular point in time. The
+// content of snapshot are guaranteed to be consistent.
+//
+// The snapshot must be released after use, by calling Release method.
+func (db *DB) GetSnapshot() (*Snapshot, error) {
+	snap := db.NewSnapshot()
+	snap.seq = uint64(C.api_snapshot_sequence_number(snap.c))
+	snap.ro = db.readOptions(nil).copy()
+	snap.ro.SetSnapshot(snap)
+	return snap, nil
+}
+
+// readOptions returns the options to read the snapshot with. Unless they
+// are the snapshot's own defaults they must be destroyed by the caller.
+func (snap *Snapshot) readOptions(ro *opt.ReadOptions) *ReadOptions {
+	if ro == nil && snap.ro != nil {
+		return snap.ro
+	}
+	opts := snap.db.readOptions(ro).copy()
+	opts.SetSnapshot(snap)
+	return opts
+}
+
+// Get gets the value for the given key. It returns ErrNotFound if
+// the DB does not contains the key.
+//
+// The caller should not modify the contents of the returned slice, but
+// it is safe to modify the contents of the argument after Get returns.
+func (snap *Snapshot) Get(key []byte, ro *opt.ReadOptions) ([]byte, error) {
+	snap.mu.RLock()
+	defer snap.mu.RUnlock()
+	if snap.released {
+		return nil, errors.ErrSnapshotReleased
+	}
+	opts := snap.readOptions(ro)
+	if opts != snap.ro {
+		defer opts.Destroy()
+	}
+	return snap.db.GetBytes(opts, key)
+}
+
+// Has returns true if the DB does contains the given key.
+//
+// It is safe to modify the contents of the argument after Has returns.
+func (snap *Snapshot) Has(key []byte, ro *opt.ReadOptions) (bool, error) {
+	snap.mu.RLock()
+	defer snap.mu.RUnlock()
+	if snap.released {
+		return false, errors.ErrSnapshotReleased
+	}
+	opts := snap.readOptions(ro)
+	if opts != snap.ro {
+		defer opts.Destroy()
+	}
+	return snap.db.has(key, opts)
+}
+
+// NewIterator returns an iterator for the snapshot of the underlying DB,
+// limited to the keys within slice as with DB.NewIterator.
+// Releasing the snapshot does not release the iterator; the iterator must
+// be released on its own.
+//
+// If the snapshot has been released the iterator is empty and its Error
+// method returns ErrSnapshotReleased.
+func (snap *Snapshot) NewIterator(slice *Range, ro *opt.ReadOptions) *iterator.Iterator {
+	snap.mu.RLock()
+	defer snap.mu.RUnlock()
+	if snap.released {
+		return iterator.NewEmptyIterator(errors.ErrSnapshotReleased)
+	}
+	opts := snap.readOptions(ro)
+	if opts != snap.ro {
+		// The native iterator keeps its own copy of the options.
+		defer opts.Destroy()
+	}
+	return snap.db.newIterator(slice, opts)
+}
+
+// SequenceNumber returns the sequence number of the snapshot.
+func (snap *Snapshot) SequenceNumber() uint64 {
+	return snap.seq
+}
+
+// Release releases the snapshot. This will not release any returned
+// iterators, the iterators would still be valid until released or the
+// underlying DB is closed.
+//
+// Other methods should not be called after the snapshot has been released.
+// Release can be called multiple times without causing error. Snapshots
+// made by NewNativeSnapshot or TransactionDB.NewSnapshot do not know their
+// DB, Release does nothing on them; release them through their DB instead.
+func (snap *Snapshot) Release() {
+	if snap.db != nil {
+		snap.release(snap.db)
+	}
+}
+
+// release releases the snapshot from db.
+func (snap *Snapshot) release(db *DB) {
+	snap.mu.Lock()
+	defer snap.mu.Unlock()
+	if snap.released {
+		return
+	}
+	snap.released = true
+	C.rocksdb_release_snapshot(db.c, snap.c)
+	snap.c = nil
+	if snap.ro != nil {
+		snap.ro.Destroy()
+		snap.ro = nil
+	}
+}
+
+func (snap *Snapshot) String() string {
+	return fmt.Sprintf("rocksdb.Snapshot{%d}", snap.seq)
+}
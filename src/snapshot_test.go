@@ -0,0 +1,58 @@
+//go:build cgo
+// +build cgo
+
+package rocksdb
+
+import (
+	"testing"
+
+	"github.com/facebookgo/ensure"
+	"./opt"
+	"./util"
+)
+
+func TestSnapshot(t *testing.T) {
+	db := newTestDB(t, "TestSnapshot", nil)
+	defer db.Close()
+
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put([]byte("key1"), []byte("val1"), wo))
+
+	snap, err := db.GetSnapshot()
+	ensure.Nil(t, err)
+	ensure.Nil(t, db.Put([]byte("key1"), []byte("val2"), wo))
+	ensure.Nil(t, db.Put([]byte("key2"), []byte("val2"), wo))
+
+	v, err := snap.Get([]byte("key1"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val1"))
+	_, err = snap.Get([]byte("key2"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+	ok, err := snap.Has([]byte("key2"), &opt.ReadOptions{})
+	ensure.Nil(t, err)
+	ensure.False(t, ok)
+
+	iter := snap.NewIterator(util.BytesPrefix([]byte("key")), nil)
+	var keys []string
+	for iter.Next() {
+		keys = append(keys, string(iter.Key()))
+	}
+	ensure.Nil(t, iter.Error())
+	iter.Release()
+	ensure.DeepEqual(t, keys, []string{"key1"})
+
+	snap.Release()
+	snap.Release()
+	_, err = snap.Get([]byte("key1"), nil)
+	ensure.DeepEqual(t, err, ErrSnapshotReleased)
+	iter = snap.NewIterator(nil, nil)
+	ensure.False(t, iter.Next())
+	ensure.DeepEqual(t, iter.Error(), ErrSnapshotReleased)
+
+	// a snapshot that does not know its DB is released through the DB
+	native := NewNativeSnapshot(db.NewSnapshot().c)
+	native.Release()
+	db.ReleaseSnapshot(native)
+	_, err = native.Get([]byte("key1"), nil)
+	ensure.DeepEqual(t, err, ErrSnapshotReleased)
+}
@@ -5,7 +5,7 @@ package rocksdb
 import "C"
 
 import (
-	"errors"
+	"./errors"
 	"unsafe"
 )
 
@@ -31,7 +31,7 @@ func (w *SSTFileWriter) Open(path string) error {
 	C.rocksdb_sstfilewriter_open(w.c, cPath, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -45,7 +45,7 @@ func (w *SSTFileWriter) Add(key, value []byte) error {
 	C.rocksdb_sstfilewriter_add(w.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -56,7 +56,7 @@ func (w *SSTFileWriter) Finish() error {
 	C.rocksdb_sstfilewriter_finish(w.c, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
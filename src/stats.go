@@ -0,0 +1,80 @@
+package rocksdb
+
+import (
+	"time"
+)
+
+// Sizes is list of size.
+type Sizes []int64
+
+// Sum returns sum of the sizes.
+func (sizes Sizes) Sum() int64 {
+	var sum int64
+	for _, size := range sizes {
+		sum += size
+	}
+	return sum
+}
+
+// DBStats is database statistics, in the shape of goleveldb's DBStats.
+//
+// RocksDB reports compactions by their output level: the compactions of
+// level 0 are its flushes, counted as MemComp, those of level 1 are
+// counted as Level0Comp and the rest as NonLevel0Comp. Compaction byte
+// counts are reported by RocksDB in GB, so they are approximate.
+type DBStats struct {
+	// WriteDelayCount is the number of writes that were slowed down or
+	// stopped by a write stall.
+	WriteDelayCount int32
+
+	// WriteDelayDuration is the cumulative duration of write stalls.
+	WriteDelayDuration time.Duration
+
+	// WritePaused is whether writes are currently stopped.
+	WritePaused bool
+
+	// AliveSnapshots is the number of unreleased snapshots.
+	AliveSnapshots int32
+
+	// AliveIterators is not tracked by RocksDB and is always zero.
+	AliveIterators int32
+
+	// IOWrite and IORead are the bytes written and read by flushes and
+	// compactions.
+	IOWrite uint64
+	IORead  uint64
+
+	// BlockCacheSize is the memory used by the block cache.
+	BlockCacheSize int
+
+	// OpenedTablesCount is not tracked by RocksDB and is always zero.
+	OpenedTablesCount int
+
+	// MemTableSize is the size of all the memtables, both active and
+	// waiting to be flushed.
+	MemTableSize int
+
+	LevelSizes        Sizes
+	LevelTablesCounts []int
+	LevelRead         Sizes
+	LevelWrite        Sizes
+	LevelDurations    []time.Duration
+
+	MemComp       uint32
+	Level0Comp    uint32
+	NonLevel0Comp uint32
+
+	// SeekComp is always zero, RocksDB has no seek compactions.
+	SeekComp uint32
+}
+
+// reset clears the stats, keeping the level slices for reuse.
+func (s *DBStats) reset() {
+	*s = DBStats{
+		LevelSizes:        s.LevelSizes[:0],
+		LevelTablesCounts: s.LevelTablesCounts[:0],
+		LevelRead:         s.LevelRead[:0],
+		LevelWrite:        s.LevelWrite[:0],
+		LevelDurations:    s.LevelDurations[:0],
+	}
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"unsafe"
+)
+
+type fileLock interface {
+	release() error
+}
+
+type fileStorageLock struct {
+	fs *fileStorage
+}
+
+func (lock *fileStorageLock) Unlock() {
+	if lock.fs != nil {
+		lock.fs.mu.Lock()
+		defer lock.fs.mu.Unlock()
+		if lock.fs.slock == lock {
+			lock.fs.slock = nil
+		}
+	}
+}
+
+// fileStorage is a file-system backed storage.
+type fileStorage struct {
+	path     string
+	readOnly bool
+
+	mu    sync.Mutex
+	flock fileLock
+	slock *fileStorageLock
+	open  bool
+}
+
+// OpenFile returns a new filesystem-backed storage implementation with the
+// given path. This also acquire a file lock, so any subsequent attempt to
+// open the same path will fail with ErrLocked, both within this process and
+// from other processes.
+//
+// The storage must be closed after use, by calling Close method.
+func OpenFile(path string, readOnly bool) (Storage, error) {
+	if fi, err := os.Stat(path); err == nil {
+		if !fi.IsDir() {
+			return nil, &os.PathError{Op: "open", Path: path, Err: os.ErrInvalid}
+		}
+	} else if os.IsNotExist(err) && !readOnly {
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return nil, err
+		}
+	} else {
+		return nil, err
+	}
+
+	flock, err := newFileLock(filepath.Join(path, "LOCK"), readOnly)
+	if err != nil {
+		return nil, err
+	}
+	return &fileStorage{
+		path:     path,
+		readOnly: readOnly,
+		flock:    flock,
+		open:     true,
+	}, nil
+}
+
+func (fs *fileStorage) Lock() (Locker, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if !fs.open {
+		return nil, ErrClosed
+	}
+	if fs.slock != nil {
+		return nil, ErrLocked
+	}
+	fs.slock = &fileStorageLock{fs: fs}
+	return fs.slock, nil
+}
+
+func (fs *fileStorage) Path() string {
+	return fs.path
+}
+
+func (fs *fileStorage) Env() unsafe.Pointer {
+	return nil
+}
+
+func (fs *fileStorage) ReadOnly() bool {
+	return fs.readOnly
+}
+
+func (fs *fileStorage) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if !fs.open {
+		return nil
+	}
+	fs.open = false
+	return fs.flock.release()
+}
@@ -0,0 +1,76 @@
+package storage
+
+//#include "api.h"
+//#cgo CFLAGS: -I..
+//#cgo LDFLAGS: -lrocksdb -lstdc++ -lm -lz -lbz2 -lsnappy
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+// memPath is the DB directory within the in-memory environment.
+const memPath = "/memdb"
+
+type memStorageLock struct {
+	ms *memStorage
+}
+
+func (lock *memStorageLock) Unlock() {
+	ms := lock.ms
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.slock == lock {
+		ms.slock = nil
+	}
+}
+
+// memStorage is a memory-backed storage.
+type memStorage struct {
+	c *C.rocksdb_env_t
+
+	mu    sync.Mutex
+	slock *memStorageLock
+}
+
+// NewMemStorage returns a new memory-backed storage implementation, backed
+// by RocksDB's in-memory Env. Nothing is written to disk, and the contents
+// are lost once the storage is closed.
+func NewMemStorage() Storage {
+	return &memStorage{c: C.rocksdb_create_mem_env()}
+}
+
+func (ms *memStorage) Lock() (Locker, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.c == nil {
+		return nil, ErrClosed
+	}
+	if ms.slock != nil {
+		return nil, ErrLocked
+	}
+	ms.slock = &memStorageLock{ms: ms}
+	return ms.slock, nil
+}
+
+func (ms *memStorage) Path() string {
+	return memPath
+}
+
+func (ms *memStorage) Env() unsafe.Pointer {
+	return unsafe.Pointer(ms.c)
+}
+
+func (ms *memStorage) ReadOnly() bool {
+	return false
+}
+
+func (ms *memStorage) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.c != nil {
+		C.rocksdb_env_destroy(ms.c)
+		ms.c = nil
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+//go:build !cgo
+// +build !cgo
+
+package storage
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// memSeq numbers the memory storages, so that each has its own path.
+var memSeq uint64
+
+type memStorageLock struct {
+	ms *memStorage
+}
+
+func (lock *memStorageLock) Unlock() {
+	ms := lock.ms
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.slock == lock {
+		ms.slock = nil
+	}
+}
+
+// memStorage is a memory-backed storage.
+type memStorage struct {
+	path string
+
+	mu     sync.Mutex
+	slock  *memStorageLock
+	data   interface{}
+	closed bool
+}
+
+// NewMemStorage returns a new memory-backed storage implementation. Without
+// cgo there is no native Env; the contents of the DB are held by the
+// storage itself, see Attach. They are lost once the storage is closed.
+func NewMemStorage() Storage {
+	return &memStorage{path: fmt.Sprintf("/memdb-%d", atomic.AddUint64(&memSeq, 1))}
+}
+
+func (ms *memStorage) Lock() (Locker, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.closed {
+		return nil, ErrClosed
+	}
+	if ms.slock != nil {
+		return nil, ErrLocked
+	}
+	ms.slock = &memStorageLock{ms: ms}
+	return ms.slock, nil
+}
+
+// Attach returns the DB contents held by the storage, calling create to
+// make them if the storage holds none yet.
+func (ms *memStorage) Attach(create func() interface{}) interface{} {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	if ms.data == nil {
+		ms.data = create()
+	}
+	return ms.data
+}
+
+func (ms *memStorage) Path() string {
+	return ms.path
+}
+
+func (ms *memStorage) Env() unsafe.Pointer {
+	return nil
+}
+
+func (ms *memStorage) ReadOnly() bool {
+	return false
+}
+
+func (ms *memStorage) Close() error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.closed = true
+	ms.data = nil
+	return nil
+}
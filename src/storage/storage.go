@@ -2,21 +2,130 @@ package storage
 
 import (
   "errors"
+  "fmt"
+  "unsafe"
   . "../constants"
 )
 
-// File type
+// FileType represent a file type.
 type FileType int
 
+// File types.
+const (
+	TypeManifest FileType = 1 << iota
+	TypeJournal
+	TypeTable
+	TypeTemp
+
+	TypeAll = TypeManifest | TypeJournal | TypeTable | TypeTemp
+)
+
+func (t FileType) String() string {
+	switch t {
+	case TypeManifest:
+		return "manifest"
+	case TypeJournal:
+		return "journal"
+	case TypeTable:
+		return "table"
+	case TypeTemp:
+		return "temp"
+	}
+	return fmt.Sprintf("<unknown:%d>", t)
+}
+
 // File descriptor
 type FileDesc struct {
 	Type FileType
 	Num  int64
 }
 
+func (fd FileDesc) String() string {
+	switch fd.Type {
+	case TypeManifest:
+		return fmt.Sprintf("MANIFEST-%06d", fd.Num)
+	case TypeJournal:
+		return fmt.Sprintf("%06d.log", fd.Num)
+	case TypeTable:
+		return fmt.Sprintf("%06d.sst", fd.Num)
+	case TypeTemp:
+		return fmt.Sprintf("%06d.tmp", fd.Num)
+	default:
+		return fmt.Sprintf("%#x-%d", fd.Type, fd.Num)
+	}
+}
+
+// Zero returns true if fd == (FileDesc{}).
+func (fd FileDesc) Zero() bool {
+	return fd == (FileDesc{})
+}
+
+// FileDescOk returns true if fd is a valid 'file descriptor'.
+func FileDescOk(fd FileDesc) bool {
+	switch fd.Type {
+	case TypeManifest:
+	case TypeJournal:
+	case TypeTable:
+	case TypeTemp:
+	default:
+		return false
+	}
+	return fd.Num >= 0
+}
+
 // Common error
 var (
 	ErrInvalidFile = errors.New(PkgName+"/storage: invalid file for argument")
 	ErrLocked      = errors.New(PkgName+"/storage: already locked")
 	ErrClosed      = errors.New(PkgName+"/storage: closed")
-)
\ No newline at end of file
+)
+
+// ErrCorrupted is the type that wraps errors that indicate corruption of
+// a file. Package storage has its own type instead of using
+// errors.ErrCorrupted to prevent circular import.
+type ErrCorrupted struct {
+	Fd  FileDesc
+	Err error
+}
+
+func (e *ErrCorrupted) Error() string {
+	if !e.Fd.Zero() {
+		return fmt.Sprintf("%v [file=%v]", e.Err, e.Fd)
+	}
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrCorrupted) Unwrap() error { return e.Err }
+
+// Locker is the interface that wraps Unlock method.
+type Locker interface {
+	Unlock()
+}
+
+// Storage is the storage a DB lives in. RocksDB manages the files of a DB
+// by itself; a Storage decides where they are kept and through which
+// environment they are accessed, and guards them against concurrent use.
+//
+// Storage is safe for concurrent use.
+type Storage interface {
+	// Lock locks the storage. Any subsequent attempt to call Lock will fail
+	// until the last lock released.
+	// Caller should call Unlock method after use.
+	Lock() (Locker, error)
+
+	// Path returns the path of the DB directory within the storage.
+	Path() string
+
+	// Env returns the native rocksdb_env_t the DB files are accessed through,
+	// or nil for the default environment. It stays owned by the storage.
+	Env() unsafe.Pointer
+
+	// ReadOnly returns true if the storage was opened in read-only mode.
+	ReadOnly() bool
+
+	// Close closes the storage.
+	// It is valid to call Close multiple times. Other methods should not be
+	// called after the storage has been closed.
+	Close() error
+}
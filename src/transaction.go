@@ -5,8 +5,8 @@ package rocksdb
 import "C"
 
 import (
-	"errors"
 	"unsafe"
+	"./errors"
 	. "./iterator"
 	. "./util"
 )
@@ -29,7 +29,7 @@ func (transaction *Transaction) Commit() error {
 	C.rocksdb_transaction_commit(transaction.c, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -43,12 +43,13 @@ func (transaction *Transaction) Rollback() error {
 
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
 
 // Get returns the data associated with the key from the database given this transaction.
+// It returns ErrNotFound if the key is absent.
 func (transaction *Transaction) Get(opts *ReadOptions, key []byte) (*Slice, error) {
 	var (
 		cErr    *C.char
@@ -60,11 +61,33 @@ func (transaction *Transaction) Get(opts *ReadOptions, key []byte) (*Slice, erro
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
+	if cValue == nil {
+		return nil, errors.ErrNotFound
+	}
+	defer C.free(unsafe.Pointer(cValue))
 	return /*NewSlice(cValue, cValLen)*/StringToSlice(C.GoStringN(cValue, (C.int)(cValLen))), nil
 }
 
+// MultiGet returns the data associated with each of the keys from the
+// database given this transaction, in a single call to RocksDB. As with
+// DB.MultiGet, a key that is absent has a nil value and ErrNotFound.
+func (transaction *Transaction) MultiGet(opts *ReadOptions, keys [][]byte) ([][]byte, []error) {
+	if len(keys) == 0 {
+		return [][]byte{}, []error{}
+	}
+	cKeys, cKeySizes, free := cKeyList(keys)
+	defer free()
+	var (
+		cValues     = make([]*C.char, len(keys))
+		cValueSizes = make([]C.size_t, len(keys))
+		cErrs       = make([]*C.char, len(keys))
+	)
+	C.rocksdb_transaction_multi_get(transaction.c, opts.c, C.size_t(len(keys)), &cKeys[0], &cKeySizes[0], &cValues[0], &cValueSizes[0], &cErrs[0])
+	return multiGetResults(cValues, cValueSizes, cErrs)
+}
+
 // Put writes data associated with a key to the transaction.
 func (transaction *Transaction) Put(key, value []byte) error {
 	var (
@@ -77,7 +100,7 @@ func (transaction *Transaction) Put(key, value []byte) error {
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -91,7 +114,22 @@ func (transaction *Transaction) Delete(key []byte) error {
 	C.rocksdb_transaction_delete(transaction.c, cKey, C.size_t(len(key)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
+	}
+	return nil
+}
+
+// SingleDelete removes the data associated with the key from the
+// transaction, see DB.SingleDelete.
+func (transaction *Transaction) SingleDelete(key []byte) error {
+	var (
+		cErr *C.char
+		cKey = byteToChar(key)
+	)
+	C.api_transaction_single_delete(transaction.c, cKey, C.size_t(len(key)), &cErr)
+	if cErr != nil {
+		defer C.free(unsafe.Pointer(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
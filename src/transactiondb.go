@@ -4,8 +4,8 @@ package rocksdb
 //#include <stdlib.h>
 import "C"
 import (
-	"errors"
 	"unsafe"
+	"./errors"
 	. "./util"
 )
 
@@ -32,7 +32,7 @@ func OpenTransactionDb(
 		opts.c, transactionDBOpts.c, cName, &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 	return &TransactionDB{
 		name:              name,
@@ -72,6 +72,7 @@ func (db *TransactionDB) TransactionBegin(
 }
 
 // Get returns the data associated with the key from the database.
+// It returns ErrNotFound if the DB does not contain the key.
 func (db *TransactionDB) Get(opts *ReadOptions, key []byte) (*Slice, error) {
 	var (
 		cErr    *C.char
@@ -83,8 +84,12 @@ func (db *TransactionDB) Get(opts *ReadOptions, key []byte) (*Slice, error) {
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
+	if cValue == nil {
+		return nil, errors.ErrNotFound
+	}
+	defer C.free(unsafe.Pointer(cValue))
 	return /*NewSlice(cValue, cValLen)*/StringToSlice(C.GoStringN(cValue, (C.int)(cValLen))), nil
 }
 
@@ -100,7 +105,7 @@ func (db *TransactionDB) Put(opts *WriteOptions, key, value []byte) error {
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -114,7 +119,7 @@ func (db *TransactionDB) Delete(opts *WriteOptions, key []byte) error {
 	C.rocksdb_transactiondb_delete(db.c, opts.c, cKey, C.size_t(len(key)), &cErr)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return errors.New(C.GoString(cErr))
+		return errors.FromStatus(C.GoString(cErr))
 	}
 	return nil
 }
@@ -129,7 +134,7 @@ func (db *TransactionDB) NewCheckpoint() (*Checkpoint, error) {
 	)
 	if cErr != nil {
 		defer C.free(unsafe.Pointer(cErr))
-		return nil, errors.New(C.GoString(cErr))
+		return nil, errors.FromStatus(C.GoString(cErr))
 	}
 	return NewNativeCheckpoint(cCheckpoint), nil
 }
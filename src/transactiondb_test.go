@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
@@ -5,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	"./errors"
 )
 
 func TestOpenTransactionDb(t *testing.T) {
@@ -46,10 +50,8 @@ func TestTransactionDBCRUD(t *testing.T) {
 
 	// delete
 	ensure.Nil(t, db.Delete(wo, givenKey))
-	v3, err := db.Get(ro, givenKey)
-	defer v3.Free()
-	ensure.Nil(t, err)
-	ensure.True(t, v3.Data() == nil)
+	_, err = db.Get(ro, givenKey)
+	ensure.DeepEqual(t, err, ErrNotFound)
 
 	// transaction
 	txn := db.TransactionBegin(wo, to, nil)
@@ -75,10 +77,8 @@ func TestTransactionDBCRUD(t *testing.T) {
 	// rollback
 	ensure.Nil(t, txn2.Rollback())
 
-	v6, err := txn2.Get(ro, givenTxnKey2)
-	defer v6.Free()
-	ensure.Nil(t, err)
-	ensure.True(t, v6.Data() == nil)
+	_, err = txn2.Get(ro, givenTxnKey2)
+	ensure.DeepEqual(t, err, ErrNotFound)
 	// transaction
 	txn3 := db.TransactionBegin(wo, to, nil)
 	defer txn3.Destroy()
@@ -86,13 +86,40 @@ func TestTransactionDBCRUD(t *testing.T) {
 	ensure.Nil(t, txn3.Delete(givenTxnKey))
 	ensure.Nil(t, txn3.Commit())
 
-	v7, err := db.Get(ro, givenTxnKey)
-	defer v7.Free()
-	ensure.Nil(t, err)
-	ensure.True(t, v7.Data() == nil)
+	_, err = db.Get(ro, givenTxnKey)
+	ensure.DeepEqual(t, err, ErrNotFound)
 
 }
 
+func TestTransactionDBLockConflict(t *testing.T) {
+	db := newTestTransactionDB(t, "TestTransactionDBLockConflict", nil)
+	defer db.Close()
+
+	var (
+		givenKey = []byte("hello")
+		wo       = NewDefaultWriteOptions()
+		to       = NewDefaultTransactionOptions()
+	)
+	to.SetLockTimeout(10)
+
+	txn1 := db.TransactionBegin(wo, to, nil)
+	defer txn1.Destroy()
+	ensure.Nil(t, txn1.Put(givenKey, []byte("world1")))
+
+	txn2 := db.TransactionBegin(wo, to, nil)
+	defer txn2.Destroy()
+	err := txn2.Put(givenKey, []byte("world2"))
+	ensure.True(t, errors.Is(err, errors.ErrTimedOut))
+	ensure.False(t, errors.IsCorrupted(err))
+
+	var status *errors.ErrStatus
+	ensure.True(t, errors.As(err, &status))
+	ensure.DeepEqual(t, status.Code, errors.CodeTimedOut)
+
+	ensure.Nil(t, txn1.Commit())
+	ensure.Nil(t, txn2.Rollback())
+}
+
 func newTestTransactionDB(t *testing.T, name string, applyOpts func(opts *Options, transactionDBOpts *TransactionDBOptions)) *TransactionDB {
 	dir, err := ioutil.TempDir("", "gorockstransactiondb-"+name)
 	ensure.Nil(t, err)
@@ -108,3 +135,78 @@ func newTestTransactionDB(t *testing.T, name string, applyOpts func(opts *Option
 
 	return db
 }
+
+func TestTransactionDBEmptyKeysAndValues(t *testing.T) {
+	db := newTestTransactionDB(t, "TestTransactionDBEmptyKeysAndValues", nil)
+	defer db.Close()
+
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	ro := NewDefaultReadOptions()
+	defer ro.Destroy()
+	to := NewDefaultTransactionOptions()
+	defer to.Destroy()
+
+	for _, c := range emptyKVCases {
+		ensure.Nil(t, db.Put(wo, c.key, c.value), c.name)
+		v, err := db.Get(ro, c.key)
+		ensure.Nil(t, err, c.name)
+		ensure.DeepEqual(t, string(v.Data()), string(c.value), c.name)
+		v.Free()
+		ensure.Nil(t, db.Delete(wo, c.key), c.name)
+
+		txn := db.TransactionBegin(wo, to, nil)
+		ensure.Nil(t, txn.Put(c.key, c.value), c.name)
+		v, err = txn.Get(ro, c.key)
+		ensure.Nil(t, err, c.name)
+		ensure.DeepEqual(t, string(v.Data()), string(c.value), c.name)
+		v.Free()
+		ensure.Nil(t, txn.Delete(c.key), c.name)
+		_, err = txn.Get(ro, c.key)
+		ensure.DeepEqual(t, err, ErrNotFound, c.name)
+		ensure.Nil(t, txn.Commit(), c.name)
+		txn.Destroy()
+	}
+}
+
+func TestTransactionMultiGet(t *testing.T) {
+	db := newTestTransactionDB(t, "TestTransactionMultiGet", nil)
+	defer db.Close()
+
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	ro := NewDefaultReadOptions()
+	defer ro.Destroy()
+	to := NewDefaultTransactionOptions()
+	defer to.Destroy()
+
+	ensure.Nil(t, db.Put(wo, []byte("a"), []byte("1")))
+	txn := db.TransactionBegin(wo, to, nil)
+	defer txn.Destroy()
+	ensure.Nil(t, txn.Put([]byte("b"), []byte("2")))
+
+	values, errs := txn.MultiGet(ro, [][]byte{[]byte("a"), []byte("b"), []byte("c")})
+	ensure.DeepEqual(t, values, [][]byte{[]byte("1"), []byte("2"), nil})
+	ensure.DeepEqual(t, errs, []error{nil, nil, ErrNotFound})
+	ensure.Nil(t, txn.Rollback())
+}
+
+func TestTransactionSingleDelete(t *testing.T) {
+	db := newTestTransactionDB(t, "TestTransactionSingleDelete", nil)
+	defer db.Close()
+
+	wo := NewDefaultWriteOptions()
+	defer wo.Destroy()
+	ro := NewDefaultReadOptions()
+	defer ro.Destroy()
+	to := NewDefaultTransactionOptions()
+	defer to.Destroy()
+
+	ensure.Nil(t, db.Put(wo, []byte("a"), []byte("1")))
+	txn := db.TransactionBegin(wo, to, nil)
+	defer txn.Destroy()
+	ensure.Nil(t, txn.SingleDelete([]byte("a")))
+	ensure.Nil(t, txn.Commit())
+	_, err := db.Get(ro, []byte("a"))
+	ensure.DeepEqual(t, err, ErrNotFound)
+}
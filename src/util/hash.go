@@ -0,0 +1,32 @@
+package util
+
+// Hash return hash of the given data.
+func Hash(data []byte, seed uint32) uint32 {
+	// Similar to murmur hash
+	const (
+		m = uint32(0xc6a4a793)
+		r = uint32(24)
+	)
+	h := seed ^ (uint32(len(data)) * m)
+
+	for ; len(data) >= 4; data = data[4:] {
+		h += uint32(data[0]) | uint32(data[1])<<8 | uint32(data[2])<<16 | uint32(data[3])<<24
+		h *= m
+		h ^= (h >> 16)
+	}
+
+	switch len(data) {
+	case 3:
+		h += uint32(data[2]) << 16
+		fallthrough
+	case 2:
+		h += uint32(data[1]) << 8
+		fallthrough
+	case 1:
+		h += uint32(data[0])
+		h *= m
+		h ^= (h >> r)
+	}
+
+	return h
+}
@@ -0,0 +1,31 @@
+package util
+
+import (
+	"errors"
+	. "../constants"
+)
+
+var (
+	ErrReleased     = errors.New(PkgName + ": resource already relesed")
+	ErrHasReleaser  = errors.New(PkgName + ": releaser already defined")
+	ErrIterReleased = errors.New(PkgName + ": iterator released")
+)
+
+// Releaser is the interface that wraps the basic Release method.
+type Releaser interface {
+	// Release releases associated resources. Release should always success
+	// and can be called multiple times without causing error.
+	Release()
+}
+
+// ReleaseSetter is the interface that wraps the basic SetReleaser method.
+type ReleaseSetter interface {
+	// SetReleaser associates the given releaser to the resources. The
+	// releaser will be called once coresponding resources released.
+	// Calling SetReleaser with nil will clear the releaser.
+	//
+	// This will panic if a releaser already present or coresponding
+	// resource is already released. Releaser should be cleared first
+	// before assigned a new one.
+	SetReleaser(releaser Releaser)
+}
@@ -2,15 +2,8 @@ package util
 
 import "C"
 import (
-	"errors"
 	"reflect"
 	"unsafe"
-	. "../constants"
-)
-
-var (
-	ErrReleased    = errors.New(PkgName + ": resource already relesed")
-	ErrHasReleaser = errors.New(PkgName + ": releaser already defined")
 )
 
 // btoi converts a bool value to int.
@@ -3,25 +3,157 @@ package rocksdb
 //#include "api.h"
 import "C"
 import (
-	"errors"
-	"io"
+	"runtime"
 )
 
 // WriteBatch is a batching of Puts, Merges and Deletes.
 type WriteBatch struct {
+	*writeBatch
+}
+
+// writeBatch holds the native batch. It is allocated separately so that
+// copies of a WriteBatch or Batch share it, and so that a Batch can carry
+// a finalizer wherever the Batch itself lives.
+type writeBatch struct {
 	c *C.rocksdb_writebatch_t
 }
 
+// destroy is the finalizer of the native batch of a Batch. As the batch
+// may be collected while a C call still uses the native batch, the methods
+// keep it alive until their C calls return.
+func (w *writeBatch) destroy() {
+	C.rocksdb_writebatch_destroy(w.c)
+	w.c = nil
+}
+
+// Batch is a goleveldb style write batch. The zero value is ready to use;
+// its native batch is allocated on first use and freed either by Destroy
+// or, for batches that are never destroyed, by the garbage collector.
+// Copies of a Batch share the native batch.
 type Batch WriteBatch
+
+// NewBatch creates a Batch object.
+func NewBatch() *Batch {
+	b := new(Batch)
+	b.init()
+	return b
+}
+
+func (b *Batch) init() {
+	if b.writeBatch == nil {
+		b.writeBatch = new(writeBatch)
+	}
+	if b.c == nil {
+		b.c = C.rocksdb_writebatch_create()
+		runtime.SetFinalizer(b.writeBatch, (*writeBatch).destroy)
+	}
+}
+
+// native reports whether the native batch has been allocated.
+func (b *Batch) native() bool {
+	return b.writeBatch != nil && b.c != nil
+}
+
+// Put appends 'put operation' of the given key/value pair to the batch.
+// It is safe to modify the contents of the argument after Put returns.
 func (wb *Batch) Put(key, value []byte) {
+	wb.init()
 	cKey := byteToChar(key)
 	cValue := byteToChar(value)
 	C.rocksdb_writebatch_put(wb.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)))
+	runtime.KeepAlive(wb)
 }
 
+// Delete appends 'delete operation' of the given key to the batch.
+// It is safe to modify the contents of the argument after Delete returns.
 func (wb *Batch) Delete(key []byte) {
+	wb.init()
 	cKey := byteToChar(key)
 	C.rocksdb_writebatch_delete(wb.c, cKey, C.size_t(len(key)))
+	runtime.KeepAlive(wb)
+}
+
+// SingleDelete appends 'single delete operation' of the given key to the
+// batch, see DB.SingleDelete.
+// It is safe to modify the contents of the argument after SingleDelete returns.
+func (wb *Batch) SingleDelete(key []byte) {
+	wb.init()
+	cKey := byteToChar(key)
+	C.rocksdb_writebatch_singledelete(wb.c, cKey, C.size_t(len(key)))
+	runtime.KeepAlive(wb)
+}
+
+// Dump dumps batch contents in the RocksDB WriteBatch wire format. The
+// returned slice is a copy and can be passed to Load or WriteBatchFrom.
+func (wb *Batch) Dump() []byte {
+	wb.init()
+	data := append([]byte(nil), (*WriteBatch)(wb).Data()...)
+	runtime.KeepAlive(wb)
+	return data
+}
+
+// Load loads given slice into the batch. Previous contents of the batch
+// will be discarded. The records are validated before anything is
+// replaced, and the slice is copied.
+func (wb *Batch) Load(data []byte) error {
+	if err := validBatch(data); err != nil {
+		return err
+	}
+	wb.init()
+	C.rocksdb_writebatch_destroy(wb.c)
+	wb.c = C.rocksdb_writebatch_create_from(byteToChar(data), C.size_t(len(data)))
+	runtime.KeepAlive(wb)
+	return nil
+}
+
+// Replay replays batch contents. Puts are handed to r.Put, deletions and
+// single deletions to r.Delete. Records of the default column family
+// without a goleveldb equivalent, and records of other column families,
+// are skipped.
+func (wb *Batch) Replay(r BatchReplay) error {
+	if !wb.native() {
+		return nil
+	}
+	iter := (*WriteBatch)(wb).NewIterator()
+	for iter.Next() {
+		switch rec := iter.Record(); rec.Type {
+		case WriteBatchValueRecord:
+			r.Put(rec.Key, rec.Value)
+		case
+			WriteBatchDeletionRecord,
+			WriteBatchSingleDeletionRecord:
+			r.Delete(rec.Key)
+		}
+	}
+	// The iterator reads the native batch in place.
+	runtime.KeepAlive(wb)
+	return iter.Error()
+}
+
+// Len returns number of records in the batch.
+func (wb *Batch) Len() int {
+	if !wb.native() {
+		return 0
+	}
+	n := (*WriteBatch)(wb).Count()
+	runtime.KeepAlive(wb)
+	return n
+}
+
+// Reset resets the batch.
+func (wb *Batch) Reset() {
+	if wb.native() {
+		C.rocksdb_writebatch_clear(wb.c)
+		runtime.KeepAlive(wb)
+	}
+}
+
+// Destroy deallocates the native batch. The Batch may be reused afterwards.
+func (wb *Batch) Destroy() {
+	if wb.native() {
+		runtime.SetFinalizer(wb.writeBatch, nil)
+		wb.writeBatch.destroy()
+	}
 }
 
 // NewWriteBatch create a WriteBatch object.
@@ -31,7 +163,7 @@ func NewWriteBatch() *WriteBatch {
 
 // NewNativeWriteBatch create a WriteBatch object.
 func NewNativeWriteBatch(c *C.rocksdb_writebatch_t) *WriteBatch {
-	return &WriteBatch{c}
+	return &WriteBatch{&writeBatch{c}}
 }
 
 // WriteBatchFrom creates a write batch from a serialized WriteBatch.
@@ -44,6 +176,7 @@ func (wb *WriteBatch) Put(key, value []byte) {
 	cKey := byteToChar(key)
 	cValue := byteToChar(value)
 	C.rocksdb_writebatch_put(wb.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)))
+	runtime.KeepAlive(wb)
 }
 
 // PutCF queues a key-value pair in a column family.
@@ -51,6 +184,7 @@ func (wb *WriteBatch) PutCF(cf *ColumnFamilyHandle, key, value []byte) {
 	cKey := byteToChar(key)
 	cValue := byteToChar(value)
 	C.rocksdb_writebatch_put_cf(wb.c, cf.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)))
+	runtime.KeepAlive(wb)
 }
 
 // Merge queues a merge of "value" with the existing value of "key".
@@ -58,6 +192,7 @@ func (wb *WriteBatch) Merge(key, value []byte) {
 	cKey := byteToChar(key)
 	cValue := byteToChar(value)
 	C.rocksdb_writebatch_merge(wb.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)))
+	runtime.KeepAlive(wb)
 }
 
 // MergeCF queues a merge of "value" with the existing value of "key" in a
@@ -66,44 +201,85 @@ func (wb *WriteBatch) MergeCF(cf *ColumnFamilyHandle, key, value []byte) {
 	cKey := byteToChar(key)
 	cValue := byteToChar(value)
 	C.rocksdb_writebatch_merge_cf(wb.c, cf.c, cKey, C.size_t(len(key)), cValue, C.size_t(len(value)))
+	runtime.KeepAlive(wb)
 }
 
 // Delete queues a deletion of the data at key.
 func (wb *WriteBatch) Delete(key []byte) {
 	cKey := byteToChar(key)
 	C.rocksdb_writebatch_delete(wb.c, cKey, C.size_t(len(key)))
+	runtime.KeepAlive(wb)
 }
 
 // DeleteCF queues a deletion of the data at key in a column family.
 func (wb *WriteBatch) DeleteCF(cf *ColumnFamilyHandle, key []byte) {
 	cKey := byteToChar(key)
 	C.rocksdb_writebatch_delete_cf(wb.c, cf.c, cKey, C.size_t(len(key)))
+	runtime.KeepAlive(wb)
+}
+
+// SingleDelete queues a single deletion of the data at key, see
+// DB.SingleDelete.
+func (wb *WriteBatch) SingleDelete(key []byte) {
+	cKey := byteToChar(key)
+	C.rocksdb_writebatch_singledelete(wb.c, cKey, C.size_t(len(key)))
+	runtime.KeepAlive(wb)
+}
+
+// SingleDeleteCF queues a single deletion of the data at key in a column
+// family.
+func (wb *WriteBatch) SingleDeleteCF(cf *ColumnFamilyHandle, key []byte) {
+	cKey := byteToChar(key)
+	C.rocksdb_writebatch_singledelete_cf(wb.c, cf.c, cKey, C.size_t(len(key)))
+	runtime.KeepAlive(wb)
+}
+
+// DeleteRange queues a deletion of the keys from startKey, inclusive, to
+// endKey, exclusive, as a single range tombstone.
+func (wb *WriteBatch) DeleteRange(startKey, endKey []byte) {
+	cStart := byteToChar(startKey)
+	cEnd := byteToChar(endKey)
+	C.rocksdb_writebatch_delete_range(wb.c, cStart, C.size_t(len(startKey)), cEnd, C.size_t(len(endKey)))
+	runtime.KeepAlive(wb)
+}
+
+// DeleteRangeCF queues a deletion of the keys from startKey, inclusive, to
+// endKey, exclusive, in a column family.
+func (wb *WriteBatch) DeleteRangeCF(cf *ColumnFamilyHandle, startKey, endKey []byte) {
+	cStart := byteToChar(startKey)
+	cEnd := byteToChar(endKey)
+	C.rocksdb_writebatch_delete_range_cf(wb.c, cf.c, cStart, C.size_t(len(startKey)), cEnd, C.size_t(len(endKey)))
+	runtime.KeepAlive(wb)
 }
 
 // Data returns the serialized version of this batch.
 func (wb *WriteBatch) Data() []byte {
 	var cSize C.size_t
 	cValue := C.rocksdb_writebatch_data(wb.c, &cSize)
+	runtime.KeepAlive(wb)
 	return charToByte(cValue, cSize)
 }
 
 // Count returns the number of updates in the batch.
 func (wb *WriteBatch) Count() int {
-	return int(C.rocksdb_writebatch_count(wb.c))
+	n := int(C.rocksdb_writebatch_count(wb.c))
+	runtime.KeepAlive(wb)
+	return n
 }
 
 // NewIterator returns a iterator to iterate over the records in the batch.
 func (wb *WriteBatch) NewIterator() *WriteBatchIterator {
 	data := wb.Data()
-	if len(data) < 8+4 {
+	if len(data) < batchHeaderLen {
 		return &WriteBatchIterator{}
 	}
-	return &WriteBatchIterator{data: data[12:]}
+	return &WriteBatchIterator{data: data[batchHeaderLen:]}
 }
 
 // Clear removes all the enqueued Put and Deletes.
 func (wb *WriteBatch) Clear() {
 	C.rocksdb_writebatch_clear(wb.c)
+	runtime.KeepAlive(wb)
 }
 
 // Destroy deallocates the WriteBatch object.
@@ -111,160 +287,3 @@ func (wb *WriteBatch) Destroy() {
 	C.rocksdb_writebatch_destroy(wb.c)
 	wb.c = nil
 }
-
-// WriteBatchRecordType describes the type of a batch record.
-type WriteBatchRecordType byte
-
-// Types of batch records.
-const (
-	WriteBatchDeletionRecord                 WriteBatchRecordType = 0x0
-	WriteBatchValueRecord                    WriteBatchRecordType = 0x1
-	WriteBatchMergeRecord                    WriteBatchRecordType = 0x2
-	WriteBatchLogDataRecord                  WriteBatchRecordType = 0x3
-	WriteBatchCFDeletionRecord               WriteBatchRecordType = 0x4
-	WriteBatchCFValueRecord                  WriteBatchRecordType = 0x5
-	WriteBatchCFMergeRecord                  WriteBatchRecordType = 0x6
-	WriteBatchSingleDeletionRecord           WriteBatchRecordType = 0x7
-	WriteBatchCFSingleDeletionRecord         WriteBatchRecordType = 0x8
-	WriteBatchBeginPrepareXIDRecord          WriteBatchRecordType = 0x9
-	WriteBatchEndPrepareXIDRecord            WriteBatchRecordType = 0xA
-	WriteBatchCommitXIDRecord                WriteBatchRecordType = 0xB
-	WriteBatchRollbackXIDRecord              WriteBatchRecordType = 0xC
-	WriteBatchNoopRecord                     WriteBatchRecordType = 0xD
-	WriteBatchRangeDeletion                  WriteBatchRecordType = 0xF
-	WriteBatchCFRangeDeletion                WriteBatchRecordType = 0xE
-	WriteBatchCFBlobIndex                    WriteBatchRecordType = 0x10
-	WriteBatchBlobIndex                      WriteBatchRecordType = 0x11
-	WriteBatchBeginPersistedPrepareXIDRecord WriteBatchRecordType = 0x12
-	WriteBatchNotUsedRecord                  WriteBatchRecordType = 0x7F
-)
-
-// WriteBatchRecord represents a record inside a WriteBatch.
-type WriteBatchRecord struct {
-	CF    int
-	Key   []byte
-	Value []byte
-	Type  WriteBatchRecordType
-}
-
-// WriteBatchIterator represents a iterator to iterator over records.
-type WriteBatchIterator struct {
-	data   []byte
-	record WriteBatchRecord
-	err    error
-}
-
-// Next returns the next record.
-// Returns false if no further record exists.
-func (iter *WriteBatchIterator) Next() bool {
-	if iter.err != nil || len(iter.data) == 0 {
-		return false
-	}
-	// reset the current record
-	iter.record.CF = 0
-	iter.record.Key = nil
-	iter.record.Value = nil
-	// parse the record type
-	iter.record.Type = iter.decodeRecType()
-	switch iter.record.Type {
-	case
-		WriteBatchDeletionRecord,
-		WriteBatchSingleDeletionRecord:
-		iter.record.Key = iter.decodeSlice()
-	case
-		WriteBatchCFDeletionRecord,
-		WriteBatchCFSingleDeletionRecord:
-		iter.record.CF = int(iter.decodeVarint())
-		if iter.err == nil {
-			iter.record.Key = iter.decodeSlice()
-		}
-	case
-		WriteBatchValueRecord,
-		WriteBatchMergeRecord,
-		WriteBatchRangeDeletion,
-		WriteBatchBlobIndex:
-		iter.record.Key = iter.decodeSlice()
-		if iter.err == nil {
-			iter.record.Value = iter.decodeSlice()
-		}
-	case
-		WriteBatchCFValueRecord,
-		WriteBatchCFRangeDeletion,
-		WriteBatchCFMergeRecord,
-		WriteBatchCFBlobIndex:
-		iter.record.CF = int(iter.decodeVarint())
-		if iter.err == nil {
-			iter.record.Key = iter.decodeSlice()
-		}
-		if iter.err == nil {
-			iter.record.Value = iter.decodeSlice()
-		}
-	case WriteBatchLogDataRecord:
-		iter.record.Value = iter.decodeSlice()
-	case
-		WriteBatchNoopRecord,
-		WriteBatchBeginPrepareXIDRecord,
-		WriteBatchBeginPersistedPrepareXIDRecord:
-	case
-		WriteBatchEndPrepareXIDRecord,
-		WriteBatchCommitXIDRecord,
-		WriteBatchRollbackXIDRecord:
-		iter.record.Value = iter.decodeSlice()
-	default:
-		iter.err = errors.New("unsupported wal record type")
-	}
-	return iter.err == nil
-}
-
-// Record returns the current record.
-func (iter *WriteBatchIterator) Record() *WriteBatchRecord {
-	return &iter.record
-}
-
-// Error returns the error if the iteration is failed.
-func (iter *WriteBatchIterator) Error() error {
-	return iter.err
-}
-
-func (iter *WriteBatchIterator) decodeSlice() []byte {
-	l := int(iter.decodeVarint())
-	if l > len(iter.data) {
-		iter.err = io.ErrShortBuffer
-	}
-	if iter.err != nil {
-		return []byte{}
-	}
-	ret := iter.data[:l]
-	iter.data = iter.data[l:]
-	return ret
-}
-
-func (iter *WriteBatchIterator) decodeRecType() WriteBatchRecordType {
-	if len(iter.data) == 0 {
-		iter.err = io.ErrShortBuffer
-		return WriteBatchNotUsedRecord
-	}
-	t := iter.data[0]
-	iter.data = iter.data[1:]
-	return WriteBatchRecordType(t)
-}
-
-func (iter *WriteBatchIterator) decodeVarint() uint64 {
-	var n int
-	var x uint64
-	for shift := uint(0); shift < 64 && n < len(iter.data); shift += 7 {
-		b := uint64(iter.data[n])
-		n++
-		x |= (b & 0x7F) << shift
-		if (b & 0x80) == 0 {
-			iter.data = iter.data[n:]
-			return x
-		}
-	}
-	if n == len(iter.data) {
-		iter.err = io.ErrShortBuffer
-	} else {
-		iter.err = errors.New("malformed varint")
-	}
-	return 0
-}
\ No newline at end of file
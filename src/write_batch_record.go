@@ -0,0 +1,239 @@
+package rocksdb
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// BatchReplay wraps basic batch operations.
+type BatchReplay interface {
+	Put(key, value []byte)
+	Delete(key []byte)
+}
+
+// batchHeaderLen is the size of the sequence number and count that
+// prefix the serialized WriteBatch.
+const batchHeaderLen = 8 + 4
+
+// validBatch checks that data holds a well-formed serialized WriteBatch
+// whose header count matches its records.
+func validBatch(data []byte) error {
+	if len(data) < batchHeaderLen {
+		return errors.New("batch is too short")
+	}
+	iter := &WriteBatchIterator{data: data[batchHeaderLen:]}
+	n := 0
+	for iter.Next() {
+		if iter.record.Type.counted() {
+			n++
+		}
+	}
+	if iter.err != nil {
+		return iter.err
+	}
+	if n != int(binary.LittleEndian.Uint32(data[8:batchHeaderLen])) {
+		return errors.New("batch record count mismatch")
+	}
+	return nil
+}
+
+// replayable reports whether Batch.Replay hands every update read by iter
+// to the BatchReplay, that is whether the batch only holds puts and
+// deletions of the default column family.
+func replayable(iter *WriteBatchIterator) (bool, error) {
+	for iter.Next() {
+		switch iter.record.Type {
+		case
+			WriteBatchValueRecord,
+			WriteBatchDeletionRecord,
+			WriteBatchSingleDeletionRecord,
+			WriteBatchLogDataRecord,
+			WriteBatchNoopRecord:
+		default:
+			return false, nil
+		}
+	}
+	return iter.err == nil, iter.err
+}
+
+// WriteBatchRecordType describes the type of a batch record.
+type WriteBatchRecordType byte
+
+// Types of batch records.
+const (
+	WriteBatchDeletionRecord                 WriteBatchRecordType = 0x0
+	WriteBatchValueRecord                    WriteBatchRecordType = 0x1
+	WriteBatchMergeRecord                    WriteBatchRecordType = 0x2
+	WriteBatchLogDataRecord                  WriteBatchRecordType = 0x3
+	WriteBatchCFDeletionRecord               WriteBatchRecordType = 0x4
+	WriteBatchCFValueRecord                  WriteBatchRecordType = 0x5
+	WriteBatchCFMergeRecord                  WriteBatchRecordType = 0x6
+	WriteBatchSingleDeletionRecord           WriteBatchRecordType = 0x7
+	WriteBatchCFSingleDeletionRecord         WriteBatchRecordType = 0x8
+	WriteBatchBeginPrepareXIDRecord          WriteBatchRecordType = 0x9
+	WriteBatchEndPrepareXIDRecord            WriteBatchRecordType = 0xA
+	WriteBatchCommitXIDRecord                WriteBatchRecordType = 0xB
+	WriteBatchRollbackXIDRecord              WriteBatchRecordType = 0xC
+	WriteBatchNoopRecord                     WriteBatchRecordType = 0xD
+	WriteBatchRangeDeletion                  WriteBatchRecordType = 0xF
+	WriteBatchCFRangeDeletion                WriteBatchRecordType = 0xE
+	WriteBatchCFBlobIndex                    WriteBatchRecordType = 0x10
+	WriteBatchBlobIndex                      WriteBatchRecordType = 0x11
+	WriteBatchBeginPersistedPrepareXIDRecord WriteBatchRecordType = 0x12
+	WriteBatchBeginUnprepareXIDRecord        WriteBatchRecordType = 0x13
+	WriteBatchNotUsedRecord                  WriteBatchRecordType = 0x7F
+)
+
+// counted reports whether records of this type are included in the count
+// stored in the WriteBatch header.
+func (t WriteBatchRecordType) counted() bool {
+	switch t {
+	case
+		WriteBatchLogDataRecord,
+		WriteBatchNoopRecord,
+		WriteBatchBeginPrepareXIDRecord,
+		WriteBatchBeginPersistedPrepareXIDRecord,
+		WriteBatchBeginUnprepareXIDRecord,
+		WriteBatchEndPrepareXIDRecord,
+		WriteBatchCommitXIDRecord,
+		WriteBatchRollbackXIDRecord:
+		return false
+	}
+	return true
+}
+
+// WriteBatchRecord represents a record inside a WriteBatch.
+//
+// For range deletions Key is the inclusive begin and Value the exclusive
+// end key. For log data Value is the blob, for blob indexes the index entry
+// and for the end-prepare, commit and rollback markers the transaction ID.
+type WriteBatchRecord struct {
+	CF    int
+	Key   []byte
+	Value []byte
+	Type  WriteBatchRecordType
+}
+
+// WriteBatchIterator represents a iterator to iterator over records.
+type WriteBatchIterator struct {
+	data   []byte
+	record WriteBatchRecord
+	err    error
+}
+
+// Next returns the next record.
+// Returns false if no further record exists.
+func (iter *WriteBatchIterator) Next() bool {
+	if iter.err != nil || len(iter.data) == 0 {
+		return false
+	}
+	// reset the current record
+	iter.record.CF = 0
+	iter.record.Key = nil
+	iter.record.Value = nil
+	// parse the record type
+	iter.record.Type = iter.decodeRecType()
+	switch iter.record.Type {
+	case
+		WriteBatchDeletionRecord,
+		WriteBatchSingleDeletionRecord:
+		iter.record.Key = iter.decodeSlice()
+	case
+		WriteBatchCFDeletionRecord,
+		WriteBatchCFSingleDeletionRecord:
+		iter.record.CF = int(iter.decodeVarint())
+		if iter.err == nil {
+			iter.record.Key = iter.decodeSlice()
+		}
+	case
+		WriteBatchValueRecord,
+		WriteBatchMergeRecord,
+		WriteBatchRangeDeletion,
+		WriteBatchBlobIndex:
+		iter.record.Key = iter.decodeSlice()
+		if iter.err == nil {
+			iter.record.Value = iter.decodeSlice()
+		}
+	case
+		WriteBatchCFValueRecord,
+		WriteBatchCFRangeDeletion,
+		WriteBatchCFMergeRecord,
+		WriteBatchCFBlobIndex:
+		iter.record.CF = int(iter.decodeVarint())
+		if iter.err == nil {
+			iter.record.Key = iter.decodeSlice()
+		}
+		if iter.err == nil {
+			iter.record.Value = iter.decodeSlice()
+		}
+	case WriteBatchLogDataRecord:
+		iter.record.Value = iter.decodeSlice()
+	case
+		WriteBatchNoopRecord,
+		WriteBatchBeginPrepareXIDRecord,
+		WriteBatchBeginPersistedPrepareXIDRecord,
+		WriteBatchBeginUnprepareXIDRecord:
+	case
+		WriteBatchEndPrepareXIDRecord,
+		WriteBatchCommitXIDRecord,
+		WriteBatchRollbackXIDRecord:
+		iter.record.Value = iter.decodeSlice()
+	default:
+		iter.err = errors.New("unsupported wal record type")
+	}
+	return iter.err == nil
+}
+
+// Record returns the current record.
+func (iter *WriteBatchIterator) Record() *WriteBatchRecord {
+	return &iter.record
+}
+
+// Error returns the error if the iteration is failed.
+func (iter *WriteBatchIterator) Error() error {
+	return iter.err
+}
+
+func (iter *WriteBatchIterator) decodeSlice() []byte {
+	l := int(iter.decodeVarint())
+	if l > len(iter.data) {
+		iter.err = io.ErrShortBuffer
+	}
+	if iter.err != nil {
+		return []byte{}
+	}
+	ret := iter.data[:l]
+	iter.data = iter.data[l:]
+	return ret
+}
+
+func (iter *WriteBatchIterator) decodeRecType() WriteBatchRecordType {
+	if len(iter.data) == 0 {
+		iter.err = io.ErrShortBuffer
+		return WriteBatchNotUsedRecord
+	}
+	t := iter.data[0]
+	iter.data = iter.data[1:]
+	return WriteBatchRecordType(t)
+}
+
+func (iter *WriteBatchIterator) decodeVarint() uint64 {
+	var n int
+	var x uint64
+	for shift := uint(0); shift < 64 && n < len(iter.data); shift += 7 {
+		b := uint64(iter.data[n])
+		n++
+		x |= (b & 0x7F) << shift
+		if (b & 0x80) == 0 {
+			iter.data = iter.data[n:]
+			return x
+		}
+	}
+	if n == len(iter.data) {
+		iter.err = io.ErrShortBuffer
+	} else {
+		iter.err = errors.New("malformed varint")
+	}
+	return 0
+}
\ No newline at end of file
@@ -1,9 +1,14 @@
+//go:build cgo
+// +build cgo
+
 package rocksdb
 
 import (
+	"runtime"
 	"testing"
 
 	"github.com/facebookgo/ensure"
+	"./opt"
 )
 
 func TestWriteBatch(t *testing.T) {
@@ -15,8 +20,8 @@ func TestWriteBatch(t *testing.T) {
 		givenVal1 = []byte("val1")
 		givenKey2 = []byte("key2")
 	)
-	wo := NewDefaultWriteOptions()
-	ensure.Nil(t, db.Put(wo, givenKey2, []byte("foo")))
+	wo := &opt.WriteOptions{}
+	ensure.Nil(t, db.Put(givenKey2, []byte("foo"), wo))
 
 	// create and fill the write batch
 	wb := NewWriteBatch()
@@ -26,19 +31,16 @@ func TestWriteBatch(t *testing.T) {
 	ensure.DeepEqual(t, wb.Count(), 2)
 
 	// perform the batch
-	ensure.Nil(t, db.Write(wo, wb))
+	ensure.Nil(t, db.Write((*Batch)(wb), wo))
 
 	// check changes
-	ro := NewDefaultReadOptions()
-	v1, err := db.Get(ro, givenKey1)
-	defer v1.Free()
+	ro := &opt.ReadOptions{}
+	v1, err := db.Get(givenKey1, ro)
 	ensure.Nil(t, err)
-	ensure.DeepEqual(t, v1.Data(), givenVal1)
+	ensure.DeepEqual(t, v1, givenVal1)
 
-	v2, err := db.Get(ro, givenKey2)
-	defer v2.Free()
-	ensure.Nil(t, err)
-	ensure.True(t, v2.Data() == nil)
+	_, err = db.Get(givenKey2, ro)
+	ensure.DeepEqual(t, err, ErrNotFound)
 }
 
 func TestWriteBatchIterator(t *testing.T) {
@@ -73,3 +75,92 @@ func TestWriteBatchIterator(t *testing.T) {
 	// there shouldn't be any left
 	ensure.False(t, iter.Next())
 }
+
+func TestBatchReplayDumpLoad(t *testing.T) {
+	var b Batch
+	ensure.DeepEqual(t, b.Len(), 0)
+	b.Put([]byte("key1"), []byte("val1"))
+	b.Delete([]byte("key2"))
+	b.Put([]byte("key3"), []byte{})
+	ensure.DeepEqual(t, b.Len(), 3)
+
+	var loaded Batch
+	defer loaded.Destroy()
+	ensure.Nil(t, loaded.Load(b.Dump()))
+	ensure.DeepEqual(t, loaded.Len(), 3)
+
+	r := &recordingReplay{}
+	ensure.Nil(t, loaded.Replay(r))
+	ensure.DeepEqual(t, r.ops, []string{"put key1=val1", "del key2", "put key3="})
+
+	b.Reset()
+	ensure.DeepEqual(t, b.Len(), 0)
+	b.Destroy()
+
+	ensure.NotNil(t, loaded.Load([]byte("short")))
+	ensure.DeepEqual(t, loaded.Len(), 3)
+}
+
+func TestBatchEmbeddedAndCopied(t *testing.T) {
+	db := newTestDB(t, "TestBatchEmbeddedAndCopied", nil)
+	defer db.Close()
+
+	// a zero Batch needs not be the first field of its struct
+	var s struct {
+		n int
+		b Batch
+	}
+	s.b.Put([]byte("key1"), []byte("val1"))
+
+	// copies share the native batch, destroying one destroys both
+	c := s.b
+	c.Put([]byte("key2"), []byte("val2"))
+	ensure.DeepEqual(t, s.b.Len(), 2)
+	ensure.Nil(t, db.Write(&c, nil))
+	s.b.Destroy()
+	ensure.DeepEqual(t, c.Len(), 0)
+	c.Destroy()
+	runtime.GC()
+
+	v, err := db.Get([]byte("key2"), nil)
+	ensure.Nil(t, err)
+	ensure.DeepEqual(t, v, []byte("val2"))
+}
+
+func TestWriteBatchSingleDelete(t *testing.T) {
+	db := newTestDB(t, "TestWriteBatchSingleDelete", nil)
+	defer db.Close()
+
+	wb := NewWriteBatch()
+	defer wb.Destroy()
+	wb.Put([]byte("key1"), []byte("val1"))
+	wb.SingleDelete([]byte("key1"))
+	iter := wb.NewIterator()
+	ensure.True(t, iter.Next())
+	ensure.True(t, iter.Next())
+	ensure.DeepEqual(t, iter.Record().Type, WriteBatchSingleDeletionRecord)
+	ensure.DeepEqual(t, iter.Record().Key, []byte("key1"))
+	ensure.False(t, iter.Next())
+	ensure.Nil(t, db.Write((*Batch)(wb), nil))
+	_, err := db.Get([]byte("key1"), nil)
+	ensure.DeepEqual(t, err, ErrNotFound)
+
+	var b Batch
+	defer b.Destroy()
+	b.SingleDelete([]byte("key2"))
+	r := &recordingReplay{}
+	ensure.Nil(t, b.Replay(r))
+	ensure.DeepEqual(t, r.ops, []string{"del key2"})
+}
+
+type recordingReplay struct {
+	ops []string
+}
+
+func (r *recordingReplay) Put(key, value []byte) {
+	r.ops = append(r.ops, "put "+string(key)+"="+string(value))
+}
+
+func (r *recordingReplay) Delete(key []byte) {
+	r.ops = append(r.ops, "del "+string(key))
+}